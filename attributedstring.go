@@ -0,0 +1,620 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+	"howett.net/plist"
+)
+
+// AttributedString is the text of a text layer with the runs styling it
+type AttributedString struct {
+	Text string
+	Runs []AttributeRun
+}
+
+// AttributeRun styles Length characters of the text from Location,
+// counted in UTF-16 code units like NSAttributedString does
+type AttributeRun struct {
+	Location       int
+	Length         int
+	Font           *FontDescriptor
+	Color          *Color
+	Kerning        float64
+	ParagraphStyle *ParagraphStyle
+	Underline      int
+	Strikethrough  int
+	TextTransform  TextTransform
+}
+
+// DisplayText returns the text as it appears on the canvas, with the
+// text transform of each run applied. The transform of the layer style
+// only applies to text no run covers, as the style mirrors the first run
+func (t *Text) DisplayText() string {
+	if t.AttributedString == nil {
+		return ""
+	}
+	as := t.AttributedString
+
+	transform := TextTransform_None
+	if attrs := textAttributes(t.Style); attrs != nil {
+		transform = attrs.MSAttributedStringTextTransformAttribute
+	}
+
+	units := utf16.Encode([]rune(as.String))
+	var b strings.Builder
+	pos := 0
+	for _, attr := range as.Attributes {
+		if attr == nil {
+			continue
+		}
+		start := min(max(int(attr.Location), pos), len(units))
+		end := min(max(int(attr.Location+attr.Length), start), len(units))
+
+		run := TextTransform_None
+		if attr.Attributes != nil {
+			run = attr.Attributes.MSAttributedStringTextTransformAttribute
+		}
+		b.WriteString(transform.apply(string(utf16.Decode(units[pos:start]))))
+		b.WriteString(run.apply(string(utf16.Decode(units[start:end]))))
+		pos = end
+	}
+	b.WriteString(transform.apply(string(utf16.Decode(units[pos:]))))
+	return b.String()
+}
+
+func (t TextTransform) apply(s string) string {
+	switch t {
+	case TextTransform_Uppercase:
+		return strings.ToUpper(s)
+	case TextTransform_Lowercase:
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// RunText returns the part of the text styled by run
+func (s *AttributedString) RunText(run AttributeRun) string {
+	units := utf16.Encode([]rune(s.Text))
+	start := min(max(run.Location, 0), len(units))
+	end := min(max(run.Location+run.Length, start), len(units))
+	return string(utf16.Decode(units[start:end]))
+}
+
+// AttributedString decodes the NSKeyedArchiver archive of the text
+func (a *ArchivedAttributedString) AttributedString() (*AttributedString, error) {
+	k, root, err := archiveRoot(&a.Archive)
+	if err != nil {
+		return nil, err
+	}
+
+	text, ok := k.string(root["NSString"])
+	if !ok {
+		return nil, errors.New("archived attributed string has no NSString")
+	}
+	s := &AttributedString{Text: text}
+	units := len(utf16.Encode([]rune(text)))
+
+	// a string styled by a single run has no NSAttributeInfo,
+	// and NSAttributes is the dictionary of that run
+	info, ok := k.data(root["NSAttributeInfo"])
+	if !ok {
+		if root["NSAttributes"] == nil || units == 0 {
+			return s, nil
+		}
+		run, err := k.run(root["NSAttributes"], 0, units)
+		if err != nil {
+			return nil, err
+		}
+		s.Runs = append(s.Runs, run)
+		return s, nil
+	}
+
+	dicts, ok := k.array(root["NSAttributes"])
+	if !ok {
+		return nil, errors.New("archived attributed string has no NSAttributes array")
+	}
+
+	// NSAttributeInfo holds varint pairs of run length and index into NSAttributes
+	location := 0
+	for len(info) > 0 {
+		length, n := binary.Uvarint(info)
+		if n <= 0 {
+			return nil, errors.New("malformed NSAttributeInfo")
+		}
+		info = info[n:]
+		index, n := binary.Uvarint(info)
+		if n <= 0 {
+			return nil, errors.New("malformed NSAttributeInfo")
+		}
+		info = info[n:]
+
+		if index >= uint64(len(dicts)) {
+			return nil, errors.Errorf("attribute run %d uses missing attributes %d", len(s.Runs), index)
+		}
+		run, err := k.run(dicts[index], location, int(length))
+		if err != nil {
+			return nil, err
+		}
+		s.Runs = append(s.Runs, run)
+		location += int(length)
+	}
+
+	if location != units {
+		return nil, errors.Errorf("attribute runs cover %d of %d characters", location, units)
+	}
+	return s, nil
+}
+
+// SetAttributedString replaces the archive with the encoding of s
+func (a *ArchivedAttributedString) SetAttributedString(s *AttributedString) error {
+	data, err := archiveAttributedString(s)
+	if err != nil {
+		return err
+	}
+	a.Archive.SetData(data)
+	return nil
+}
+
+// SetAttributedString replaces the text and styles of the layer with s,
+// updating the archive of documents before Sketch 48 as well. The glyph
+// bounds are reset to the frame, Sketch lays the text out again when opened
+func (t *Text) SetAttributedString(s *AttributedString) error {
+	if err := s.checkRuns(); err != nil {
+		return err
+	}
+
+	if t.AttributedString == nil {
+		t.AttributedString = &MSAttributedString{Class: "attributedString"}
+	}
+	as := t.AttributedString
+	if as.ArchivedAttributedString != nil {
+		if err := as.ArchivedAttributedString.SetAttributedString(s); err != nil {
+			return err
+		}
+	}
+	old := as.Attributes
+	as.String = s.Text
+	as.Attributes = stringAttributes(s.Runs)
+	keepAttributes(old, as.Attributes)
+	t.resetGlyphBounds()
+	return nil
+}
+
+// keepAttributes copies the attributes AttributeRun does not model from
+// the old attributes to the new ones starting within their range
+func keepAttributes(old, attrs []*StringAttribute) {
+	for _, attr := range attrs {
+		for _, o := range old {
+			if o == nil || o.Attributes == nil || attr.Location < o.Location || attr.Location >= o.Location+max(o.Length, 1) {
+				continue
+			}
+			a := attr.Attributes
+			a.TextStyleVerticalAlignmentKey = o.Attributes.TextStyleVerticalAlignmentKey
+			a.NSStrokeWidth = o.Attributes.NSStrokeWidth
+			a.NSStrokeColor = o.Attributes.NSStrokeColor
+			a.Extra = o.Attributes.Extra
+			attr.Extra = o.Extra
+			break
+		}
+	}
+}
+
+// attributedString returns the text of the layer and its runs, decoded
+// from the archive of documents before Sketch 48 when there is one
+func (t *Text) attributedString() (*AttributedString, error) {
+	as := t.AttributedString
+	if as == nil {
+		return &AttributedString{}, nil
+	}
+	if as.ArchivedAttributedString != nil {
+		return as.ArchivedAttributedString.AttributedString()
+	}
+
+	s := &AttributedString{Text: as.String}
+	for _, attr := range as.Attributes {
+		if attr != nil {
+			s.Runs = append(s.Runs, attributeRun(attr))
+		}
+	}
+	return s, nil
+}
+
+// attributeRun is the run styled by an attribute of the current format
+func attributeRun(attr *StringAttribute) AttributeRun {
+	run := AttributeRun{Location: int(attr.Location), Length: int(attr.Length)}
+	if a := attr.Attributes; a != nil {
+		run.Font = a.MSAttributedStringFontAttribute
+		run.Color = a.MSAttributedStringColorAttribute
+		run.Kerning = a.Kerning
+		run.ParagraphStyle = a.ParagraphStyle
+		run.Underline = int(a.UnderlineStyle)
+		run.Strikethrough = int(a.StrikethroughStyle)
+		run.TextTransform = a.MSAttributedStringTextTransformAttribute
+	}
+	return run
+}
+
+// resetGlyphBounds sets the glyph bounds to the frame of the layer,
+// as the text can not be laid out without the fonts
+func (t *Text) resetGlyphBounds() {
+	if t.Frame == nil {
+		return
+	}
+	t.GlyphBounds = &NestedPositionCoordinates{Data: []*PositionCoordinates{
+		{X: "0", Y: "0"},
+		{X: numberOrZero(t.Frame.Width), Y: numberOrZero(t.Frame.Height)},
+	}}
+}
+
+// checkRuns checks the runs follow each other and cover the whole text
+func (s *AttributedString) checkRuns() error {
+	location := 0
+	for i, run := range s.Runs {
+		if run.Location != location || run.Length <= 0 {
+			return errors.Errorf("attribute run %d at %d+%d does not follow the previous run", i, run.Location, run.Length)
+		}
+		location += run.Length
+	}
+
+	if units := len(utf16.Encode([]rune(s.Text))); len(s.Runs) > 0 && location != units {
+		return errors.Errorf("attribute runs cover %d of %d characters", location, units)
+	}
+	return nil
+}
+
+// archiveAttributedString encodes s as an NSKeyedArchiver NSAttributedString
+func archiveAttributedString(s *AttributedString) (map[string]interface{}, error) {
+	if err := s.checkRuns(); err != nil {
+		return nil, err
+	}
+
+	k := newKeyedArchiver()
+	root := map[string]interface{}{"NSString": k.add(s.Text)}
+
+	switch {
+	case len(s.Runs) == 1:
+		uid, err := k.attributes(s.Runs[0])
+		if err != nil {
+			return nil, err
+		}
+		root["NSAttributes"] = uid
+	case len(s.Runs) > 1:
+		// runs with the same attributes share a dictionary
+		dicts := []interface{}{}
+		styles := []AttributeRun{}
+		info := []byte{}
+		for _, run := range s.Runs {
+			index := slices.IndexFunc(styles, func(style AttributeRun) bool {
+				return sameAttributes(style, run)
+			})
+			if index < 0 {
+				uid, err := k.attributes(run)
+				if err != nil {
+					return nil, err
+				}
+				dicts = append(dicts, uid)
+				styles = append(styles, run)
+				index = len(styles) - 1
+			}
+			info = binary.AppendUvarint(info, uint64(run.Length))
+			info = binary.AppendUvarint(info, uint64(index))
+		}
+		root["NSAttributes"] = k.object(map[string]interface{}{"NS.objects": dicts}, "NSArray", "NSObject")
+		root["NSAttributeInfo"] = k.add(info)
+	}
+
+	return k.archive(root, "NSConcreteAttributedString", "NSAttributedString", "NSObject"), nil
+}
+
+func sameAttributes(a, b AttributeRun) bool {
+	a.Location, a.Length = 0, 0
+	b.Location, b.Length = 0, 0
+	return reflect.DeepEqual(a, b)
+}
+
+// attributes adds the attributes dictionary of a run
+func (k *keyedArchiver) attributes(run AttributeRun) (plist.UID, error) {
+	keys := []string{}
+	values := []plist.UID{}
+	set := func(key string, uid plist.UID) {
+		keys = append(keys, key)
+		values = append(values, uid)
+	}
+
+	if run.Font != nil && run.Font.Attributes != nil {
+		size, err := parseNumber(run.Font.Attributes.Size)
+		if err != nil {
+			return 0, errors.Wrap(err, "font size")
+		}
+		attrs := k.dict(
+			[]string{"NSFontSizeAttribute", "NSFontNameAttribute"},
+			[]plist.UID{k.add(size), k.add(run.Font.Attributes.Name)},
+		)
+		set("MSAttributedStringFontAttribute", k.object(map[string]interface{}{
+			"NSFontDescriptorAttributes": attrs,
+		}, "NSFontDescriptor", "NSObject"))
+	}
+
+	if run.Color != nil {
+		components := []string{}
+		for _, n := range []json.Number{run.Color.Red, run.Color.Green, run.Color.Blue, run.Color.Alpha} {
+			f, err := parseNumber(n)
+			if err != nil {
+				return 0, errors.Wrap(err, "color component")
+			}
+			components = append(components, strconv.FormatFloat(f, 'f', -1, 64))
+		}
+		set("NSColor", k.object(map[string]interface{}{
+			"NSColorSpace": int64(1),
+			"NSRGB":        []byte(strings.Join(components, " ") + "\x00"),
+		}, "NSColor", "NSObject"))
+	}
+
+	set("NSKern", k.add(run.Kerning))
+
+	if p := run.ParagraphStyle; p != nil {
+		fields := map[string]interface{}{}
+		for key, n := range map[string]json.Number{
+			"NSAlignment":        p.Alignment,
+			"NSMaxLineHeight":    p.MaximumLineHeight,
+			"NSMinLineHeight":    p.MinimumLineHeight,
+			"NSParagraphSpacing": p.ParagraphSpacing,
+		} {
+			if n == "" {
+				continue
+			}
+			f, err := parseNumber(n)
+			if err != nil {
+				return 0, errors.Wrapf(err, "paragraph style %s", key)
+			}
+			fields[key] = f
+		}
+		if a, ok := fields["NSAlignment"].(float64); ok {
+			fields["NSAlignment"] = int64(a)
+		}
+		set("NSParagraphStyle", k.object(fields, "NSMutableParagraphStyle", "NSParagraphStyle", "NSObject"))
+	}
+
+	if run.Underline != 0 {
+		set("NSUnderline", k.add(int64(run.Underline)))
+	}
+	if run.Strikethrough != 0 {
+		set("NSStrikethrough", k.add(int64(run.Strikethrough)))
+	}
+	if run.TextTransform != TextTransform_None {
+		set("MSAttributedStringTextTransformAttribute", k.add(int64(run.TextTransform)))
+	}
+	return k.dict(keys, values), nil
+}
+
+func parseNumber(n json.Number) (float64, error) {
+	return numberOrZero(n).Float64()
+}
+
+// run decodes the attributes dictionary of a run
+func (k *keyedArchive) run(v interface{}, location, length int) (AttributeRun, error) {
+	run := AttributeRun{Location: location, Length: length}
+
+	attrs, ok := k.dict(v)
+	if !ok {
+		return run, errors.Errorf("attribute run at %d is not a dictionary", location)
+	}
+
+	var err error
+	if font, ok := attrs["MSAttributedStringFontAttribute"]; ok {
+		run.Font, err = k.font(font)
+	} else if font, ok := attrs["NSFont"]; ok {
+		run.Font, err = k.font(font)
+	}
+	if err != nil {
+		return run, errors.Wrapf(err, "attribute run at %d", location)
+	}
+
+	if color, ok := attrs["MSAttributedStringColorAttribute"]; ok {
+		run.Color, err = k.color(color)
+	} else if color, ok := attrs["NSColor"]; ok {
+		run.Color, err = k.color(color)
+	}
+	if err != nil {
+		return run, errors.Wrapf(err, "attribute run at %d", location)
+	}
+
+	if style, ok := attrs["NSParagraphStyle"]; ok {
+		run.ParagraphStyle = k.paragraphStyle(style)
+	}
+	if kern, ok := k.number(attrs["NSKern"]); ok {
+		run.Kerning = kern
+	}
+	if underline, ok := k.number(attrs["NSUnderline"]); ok {
+		run.Underline = int(underline)
+	}
+	if strike, ok := k.number(attrs["NSStrikethrough"]); ok {
+		run.Strikethrough = int(strike)
+	}
+	if transform, ok := k.number(attrs["MSAttributedStringTextTransformAttribute"]); ok {
+		run.TextTransform = TextTransform(transform)
+	}
+	return run, nil
+}
+
+// font decodes an archived NSFontDescriptor, or an NSFont
+func (k *keyedArchive) font(v interface{}) (*FontDescriptor, error) {
+	obj, ok := k.resolve(v).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("font is not an object")
+	}
+
+	attrs := &FontDescriptorAttributes{}
+	if desc, ok := k.dict(obj["NSFontDescriptorAttributes"]); ok {
+		attrs.Name, _ = k.string(desc["NSFontNameAttribute"])
+		if size, ok := k.number(desc["NSFontSizeAttribute"]); ok {
+			attrs.Size = formatNumber(size)
+		}
+	} else {
+		attrs.Name, _ = k.string(obj["NSName"])
+		if size, ok := k.number(obj["NSSize"]); ok {
+			attrs.Size = formatNumber(size)
+		}
+	}
+	if attrs.Name == "" {
+		return nil, errors.New("font has no name")
+	}
+
+	return &FontDescriptor{Class: "fontDescriptor", Attributes: attrs}, nil
+}
+
+// color decodes an archived NSColor in an RGB or grayscale color space,
+// colors in other color spaces decode to nil
+func (k *keyedArchive) color(v interface{}) (*Color, error) {
+	obj, ok := k.resolve(v).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("color is not an object")
+	}
+
+	space, _ := k.number(obj["NSColorSpace"])
+	var components []float64
+	var err error
+	switch space {
+	case 1, 2:
+		b, _ := k.data(obj["NSRGB"])
+		components, err = colorComponents(b)
+		if err == nil && len(components) == 3 {
+			components = append(components, 1)
+		}
+	case 3, 4:
+		b, _ := k.data(obj["NSWhite"])
+		components, err = colorComponents(b)
+		if err == nil && len(components) == 1 {
+			components = append(components, 1)
+		}
+		if len(components) == 2 {
+			components = []float64{components[0], components[0], components[0], components[1]}
+		}
+	default:
+		// catalog and pattern colors have no components
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(components) != 4 {
+		return nil, errors.Errorf("color has %d components", len(components))
+	}
+
+	return &Color{
+		Class: "color",
+		Red:   formatNumber(components[0]),
+		Green: formatNumber(components[1]),
+		Blue:  formatNumber(components[2]),
+		Alpha: formatNumber(components[3]),
+	}, nil
+}
+
+// colorComponents parses the NUL terminated, space separated
+// components of an archived NSColor
+func colorComponents(b []byte) ([]float64, error) {
+	fields := strings.Fields(strings.TrimRight(string(b), "\x00"))
+	components := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "color component")
+		}
+		components = append(components, f)
+	}
+	return components, nil
+}
+
+// paragraphStyle decodes an archived NSParagraphStyle
+func (k *keyedArchive) paragraphStyle(v interface{}) *ParagraphStyle {
+	obj, ok := k.resolve(v).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	style := &ParagraphStyle{Class: "paragraphStyle"}
+	if n, ok := k.number(obj["NSAlignment"]); ok {
+		style.Alignment = formatNumber(n)
+	}
+	if n, ok := k.number(obj["NSMaxLineHeight"]); ok {
+		style.MaximumLineHeight = formatNumber(n)
+	}
+	if n, ok := k.number(obj["NSMinLineHeight"]); ok {
+		style.MinimumLineHeight = formatNumber(n)
+	}
+	if n, ok := k.number(obj["NSParagraphSpacing"]); ok {
+		style.ParagraphSpacing = formatNumber(n)
+	}
+	return style
+}
+
+// unarchiveFont fills a font descriptor from its archive
+func unarchiveFont(f *FontDescriptor) error {
+	if f == nil || f.Archive == nil || f.Attributes != nil {
+		return nil
+	}
+
+	k, root, err := archiveRoot(f.Archive)
+	if err != nil {
+		return err
+	}
+	font, err := k.font(root)
+	if err != nil {
+		return err
+	}
+	f.Class = font.Class
+	f.Attributes = font.Attributes
+	return nil
+}
+
+// unarchiveColor fills a color from its archive
+func unarchiveColor(c *Color) error {
+	if c == nil || c.Archive == nil || c.Class != "" {
+		return nil
+	}
+
+	k, root, err := archiveRoot(c.Archive)
+	if err != nil {
+		return err
+	}
+	color, err := k.color(root)
+	if err != nil || color == nil {
+		return err
+	}
+	c.Class = color.Class
+	c.Alpha = color.Alpha
+	c.Blue = color.Blue
+	c.Green = color.Green
+	c.Red = color.Red
+	return nil
+}
+
+// unarchiveParagraphStyle fills a paragraph style from its archive
+func unarchiveParagraphStyle(p *ParagraphStyle) error {
+	if p == nil || p.Archive == nil || p.Class != "" {
+		return nil
+	}
+
+	k, root, err := archiveRoot(p.Archive)
+	if err != nil {
+		return err
+	}
+	style := k.paragraphStyle(root)
+	p.Class = style.Class
+	p.Alignment = style.Alignment
+	p.MaximumLineHeight = style.MaximumLineHeight
+	p.MinimumLineHeight = style.MinimumLineHeight
+	p.ParagraphSpacing = style.ParagraphSpacing
+	return nil
+}
+
+func formatNumber(f float64) json.Number {
+	return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+}
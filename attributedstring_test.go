@@ -0,0 +1,326 @@
+package sketch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"howett.net/plist"
+)
+
+// reencode encodes a through Archive.MarshalJSON and decodes the result
+func reencode(t *testing.T, a *ArchivedAttributedString) *ArchivedAttributedString {
+	t.Helper()
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &ArchivedAttributedString{}
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestArchivedAttributedStringRoundTrip(t *testing.T) {
+	bold := AttributeRun{
+		Font:           &FontDescriptor{Class: "fontDescriptor", Attributes: &FontDescriptorAttributes{Name: "Helvetica-Bold", Size: "18"}},
+		Color:          &Color{Class: "color", Red: "1", Green: "0.5", Blue: "0", Alpha: "1"},
+		Kerning:        1.5,
+		ParagraphStyle: &ParagraphStyle{Class: "paragraphStyle", Alignment: "2", MaximumLineHeight: "20", MinimumLineHeight: "20"},
+		Underline:      1,
+		TextTransform:  TextTransform_Uppercase,
+	}
+	wave := AttributeRun{
+		Font:          &FontDescriptor{Class: "fontDescriptor", Attributes: &FontDescriptorAttributes{Name: "AppleColorEmoji", Size: "14.5"}},
+		Color:         &Color{Class: "color", Red: "0.25", Green: "0.25", Blue: "0.25", Alpha: "0.5"},
+		Strikethrough: 1,
+	}
+
+	// the emoji is a surrogate pair, two UTF-16 code units
+	s := &AttributedString{Text: "Hi 👋 Hi"}
+	for _, run := range []struct {
+		style            AttributeRun
+		location, length int
+	}{{bold, 0, 3}, {wave, 3, 2}, {bold, 5, 3}} {
+		r := run.style
+		r.Location, r.Length = run.location, run.length
+		s.Runs = append(s.Runs, r)
+	}
+	if got := s.RunText(s.Runs[1]); got != "👋" {
+		t.Fatalf("run 1 is %q", got)
+	}
+
+	a := &ArchivedAttributedString{}
+	if err := a.SetAttributedString(s); err != nil {
+		t.Fatal(err)
+	}
+	a = reencode(t, a)
+
+	data, err := a.Archive.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, root, err := archiveRoot(&a.Archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dicts, _ := k.array(root["NSAttributes"]); len(dicts) != 2 {
+		t.Fatalf("got %d attribute dictionaries, want the first and last runs to share one", len(dicts))
+	}
+	if info, _ := k.data(root["NSAttributeInfo"]); string(info) != "\x03\x00\x02\x01\x03\x00" {
+		t.Fatalf("got NSAttributeInfo %q", info)
+	}
+	if data["$archiver"] != "NSKeyedArchiver" {
+		t.Fatalf("got $archiver %v", data["$archiver"])
+	}
+
+	got, err := a.AttributedString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Fatalf("got %+v, want %+v", got, s)
+	}
+}
+
+// greyArchive is an attributed string styled by a grayscale NSColor
+func greyArchive(space int64, white string) map[string]interface{} {
+	k := newKeyedArchiver()
+	color := k.object(map[string]interface{}{
+		"NSColorSpace": space,
+		"NSWhite":      []byte(white + "\x00"),
+	}, "NSColor", "NSObject")
+	attrs := k.dict([]string{"NSColor"}, []plist.UID{color})
+	return k.archive(map[string]interface{}{
+		"NSString":     k.add("grey"),
+		"NSAttributes": attrs,
+	}, "NSConcreteAttributedString", "NSAttributedString", "NSObject")
+}
+
+func TestArchivedAttributedStringGrey(t *testing.T) {
+	tests := []struct {
+		space int64
+		white string
+		want  *Color
+	}{
+		{3, "0.5 0.25", &Color{Class: "color", Red: "0.5", Green: "0.5", Blue: "0.5", Alpha: "0.25"}},
+		{4, "1", &Color{Class: "color", Red: "1", Green: "1", Blue: "1", Alpha: "1"}},
+	}
+	for _, tt := range tests {
+		a := &ArchivedAttributedString{}
+		a.Archive.SetData(greyArchive(tt.space, tt.white))
+		s, err := reencode(t, a).AttributedString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Runs) != 1 || !reflect.DeepEqual(s.Runs[0].Color, tt.want) {
+			t.Fatalf("space %d: got runs %+v, want color %+v", tt.space, s.Runs, tt.want)
+		}
+
+		// grey is written back as RGB
+		if err := a.SetAttributedString(s); err != nil {
+			t.Fatal(err)
+		}
+		s, err = reencode(t, a).AttributedString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(s.Runs[0].Color, tt.want) {
+			t.Fatalf("space %d: got %+v after re-encoding, want %+v", tt.space, s.Runs[0].Color, tt.want)
+		}
+	}
+}
+
+// infoArchive is an attributed string with the given NSAttributeInfo
+// and two attribute dictionaries
+func infoArchive(text string, info []byte) map[string]interface{} {
+	k := newKeyedArchiver()
+	dicts := []interface{}{
+		k.dict([]string{"NSKern"}, []plist.UID{k.add(1.0)}),
+		k.dict([]string{"NSKern"}, []plist.UID{k.add(2.0)}),
+	}
+	return k.archive(map[string]interface{}{
+		"NSString":        k.add(text),
+		"NSAttributes":    k.object(map[string]interface{}{"NS.objects": dicts}, "NSArray", "NSObject"),
+		"NSAttributeInfo": k.add(info),
+	}, "NSConcreteAttributedString", "NSAttributedString", "NSObject")
+}
+
+func TestArchivedAttributedStringInfo(t *testing.T) {
+	tests := []struct {
+		info []byte
+		err  string
+	}{
+		{[]byte{2, 0, 3, 1}, ""},
+		{[]byte{2, 0, 0x83}, "malformed NSAttributeInfo"},
+		{[]byte{2}, "malformed NSAttributeInfo"},
+		{[]byte{2, 0, 3, 2}, "uses missing attributes 2"},
+		{[]byte{2, 0, 2, 1}, "cover 4 of 5 characters"},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0}, "cover"},
+	}
+	for _, tt := range tests {
+		a := &ArchivedAttributedString{}
+		a.Archive.SetData(infoArchive("hello", tt.info))
+		s, err := reencode(t, a).AttributedString()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("info %x: %v", tt.info, err)
+		case tt.err == "" && (len(s.Runs) != 2 || s.Runs[1].Kerning != 2):
+			t.Errorf("info %x: got runs %+v", tt.info, s.Runs)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("info %x: got error %v, want %q", tt.info, err, tt.err)
+		}
+	}
+}
+
+// textLayer is a text layer styled by the given transform, with
+// an attribute of the transform for each run
+func textLayer(text string, style TextTransform, runs ...StringAttribute) *Text {
+	attrs := []*StringAttribute{}
+	for i := range runs {
+		attrs = append(attrs, &runs[i])
+	}
+	return &Text{
+		LayerBase: LayerBase{Style: &Style{TextStyle: &TextStyle{
+			EncodedAttributes: &EncodedAttributes{MSAttributedStringTextTransformAttribute: style},
+		}}},
+		AttributedString: &MSAttributedString{String: text, Attributes: attrs},
+	}
+}
+
+func transformRun(location, length int64, transform TextTransform) StringAttribute {
+	return StringAttribute{Location: location, Length: length, Attributes: &EncodedAttributes{MSAttributedStringTextTransformAttribute: transform}}
+}
+
+func TestDisplayText(t *testing.T) {
+	tests := []struct {
+		text *Text
+		want string
+	}{
+		{&Text{}, ""},
+		{textLayer("Hello World", TextTransform_Uppercase), "HELLO WORLD"},
+		{textLayer("Hello World", TextTransform_Uppercase,
+			transformRun(0, 6, TextTransform_Uppercase),
+			transformRun(6, 5, TextTransform_None)), "HELLO World"},
+		{textLayer("Hello World", TextTransform_None,
+			transformRun(0, 6, TextTransform_None),
+			transformRun(6, 5, TextTransform_Lowercase)), "Hello world"},
+		// text past the runs takes the transform of the style
+		{textLayer("Hello World", TextTransform_Lowercase,
+			transformRun(0, 6, TextTransform_Uppercase)), "HELLO world"},
+		// runs are in UTF-16 code units
+		{textLayer("👋 hi there", TextTransform_None,
+			transformRun(0, 5, TextTransform_Uppercase),
+			StringAttribute{Location: 5, Length: 6}), "👋 HI there"},
+	}
+	for _, tt := range tests {
+		if got := tt.text.DisplayText(); got != tt.want {
+			t.Errorf("DisplayText() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTextEnums(t *testing.T) {
+	text := &Text{}
+	err := json.Unmarshal([]byte(`{"_class":"text","textBehaviour":2,"attributedString":{"_class":"attributedString","string":"a",`+
+		`"attributes":[{"_class":"stringAttribute","location":0,"length":1,"attributes":{"MSAttributedStringTextTransformAttribute":1}}]}}`), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text.TextBehaviour != TextBehaviour_Fixed || text.TextBehaviour.String() != "TextBehaviour_Fixed" {
+		t.Errorf("got text behaviour %v", text.TextBehaviour)
+	}
+	transform := text.AttributedString.Attributes[0].Attributes.MSAttributedStringTextTransformAttribute
+	if transform != TextTransform_Uppercase || transform.String() != "TextTransform_Uppercase" {
+		t.Errorf("got text transform %v", transform)
+	}
+	if got := TextTransform(3).String(); got != "TextTransform(3)" {
+		t.Errorf("got %q for an unknown transform", got)
+	}
+}
+
+// Texts of documents before Sketch 48 keep the transform of each run
+func TestMigrateArchivedTextTransform(t *testing.T) {
+	s := &AttributedString{Text: "Hello World", Runs: []AttributeRun{
+		{Location: 0, Length: 6, TextTransform: TextTransform_Uppercase},
+		{Location: 6, Length: 5},
+	}}
+	a := &ArchivedAttributedString{}
+	if err := a.SetAttributedString(s); err != nil {
+		t.Fatal(err)
+	}
+
+	text := textLayer("", TextTransform_Uppercase)
+	text.AttributedString = &MSAttributedString{ArchivedAttributedString: reencode(t, a)}
+	if err := migrateArchivedText(text); err != nil {
+		t.Fatal(err)
+	}
+	if got := text.DisplayText(); got != "HELLO World" {
+		t.Fatalf("DisplayText() = %q after migrating, want %q", got, "HELLO World")
+	}
+}
+
+// legacyArchive is a text styled by a single run as Sketch 43 archived
+// it, with an NSFont, an RGB NSColor and an NSParagraphStyle
+func legacyArchive() map[string]interface{} {
+	k := newKeyedArchiver()
+	font := k.object(map[string]interface{}{
+		"NSName": k.add("Avenir-Heavy"),
+		"NSSize": 16.0,
+	}, "NSFont", "NSObject")
+	color := k.object(map[string]interface{}{
+		"NSColorSpace": int64(1),
+		"NSRGB":        []byte("0.2 0.4 0.6\x00"),
+	}, "NSColor", "NSObject")
+	style := k.object(map[string]interface{}{
+		"NSAlignment":     int64(1),
+		"NSMaxLineHeight": 24.0,
+		"NSMinLineHeight": 22.0,
+	}, "NSMutableParagraphStyle", "NSParagraphStyle", "NSObject")
+	attrs := k.dict(
+		[]string{"NSFont", "NSColor", "NSParagraphStyle", "NSKern", "NSStrikethrough"},
+		[]plist.UID{font, color, style, k.add(0.5), k.add(int64(1))},
+	)
+	return k.archive(map[string]interface{}{
+		"NSString":     k.add("Hello"),
+		"NSAttributes": attrs,
+	}, "NSConcreteAttributedString", "NSAttributedString", "NSObject")
+}
+
+func TestMigrateArchivedText(t *testing.T) {
+	a := &ArchivedAttributedString{}
+	a.Archive.SetData(legacyArchive())
+	text := &Text{AttributedString: &MSAttributedString{ArchivedAttributedString: reencode(t, a)}}
+	if err := migrateArchivedText(text); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*StringAttribute{{
+		Class:    "stringAttribute",
+		Location: 0,
+		Length:   5,
+		Attributes: &EncodedAttributes{
+			Kerning:                          0.5,
+			MSAttributedStringColorAttribute: &Color{Class: "color", Red: "0.2", Green: "0.4", Blue: "0.6", Alpha: "1"},
+			MSAttributedStringFontAttribute:  &FontDescriptor{Class: "fontDescriptor", Attributes: &FontDescriptorAttributes{Name: "Avenir-Heavy", Size: "16"}},
+			ParagraphStyle:                   &ParagraphStyle{Class: "paragraphStyle", Alignment: "1", MaximumLineHeight: "24", MinimumLineHeight: "22"},
+			StrikethroughStyle:               1,
+		},
+	}}
+	as := text.AttributedString
+	if as.String != "Hello" || !reflect.DeepEqual(as.Attributes, want) {
+		t.Fatalf("got %q with attributes %+v", as.String, as.Attributes[0].Attributes)
+	}
+
+	// texts already holding their string and attributes are left alone
+	as.String = "Kept"
+	as.Attributes = []*StringAttribute{}
+	if err := migrateArchivedText(text); err != nil {
+		t.Fatal(err)
+	}
+	if as.String != "Kept" || len(as.Attributes) != 0 {
+		t.Fatalf("got %q with %d attributes after migrating again", as.String, len(as.Attributes))
+	}
+}
@@ -1,4 +1,4 @@
-//go:generate stringer -type=ResizingType,LayerListExpandedType,BorderPosition,BorderLineCapStyle,BorderLineJoinStyle,FillType,PatternFillType,BlendMode,LineDecorationType,BooleanOperationType,CurveMode -output constants_strings.go constants.go
+//go:generate stringer -type=ResizingType,LayerListExpandedType,BorderPosition,BorderLineCapStyle,BorderLineJoinStyle,FillType,PatternFillType,BlendMode,LineDecorationType,BooleanOperationType,CurveMode,TextTransform,TextBehaviour -output constants_strings.go constants.go
 package sketch
 
 type ResizingType int64
@@ -111,3 +111,19 @@ const (
 	CurveMode_Disconnected
 	CurveMode_Asymmetric
 )
+
+type TextTransform int64 // 0 | 1 | 2
+
+const (
+	TextTransform_None TextTransform = iota
+	TextTransform_Uppercase
+	TextTransform_Lowercase
+)
+
+type TextBehaviour int64 // 0 | 1 | 2
+
+const (
+	TextBehaviour_AutoWidth TextBehaviour = iota
+	TextBehaviour_AutoHeight
+	TextBehaviour_Fixed
+)
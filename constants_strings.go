@@ -1,18 +1,37 @@
-// Code generated by "stringer -type=ResizingType,LayerListExpandedType,BorderPosition,BorderLineCapStyle,BorderLineJoinStyle,FillType,PatternFillType,BlendMode,LineDecorationType,BooleanOperationType,CurveMode -output constants_strings.go constants.go"; DO NOT EDIT.
+// Code generated by "stringer -type=ResizingType,LayerListExpandedType,BorderPosition,BorderLineCapStyle,BorderLineJoinStyle,FillType,PatternFillType,BlendMode,LineDecorationType,BooleanOperationType,CurveMode,TextTransform,TextBehaviour -output constants_strings.go constants.go"; DO NOT EDIT.
 
 package sketch
 
-import "fmt"
+import "strconv"
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[ResizingType_Stretch-0]
+	_ = x[ResizingType_PinToCorner-1]
+	_ = x[ResizingType_ResizeObject-2]
+	_ = x[ResizingType_FloatInPlace-3]
+}
 
 const _ResizingType_name = "ResizingType_StretchResizingType_PinToCornerResizingType_ResizeObjectResizingType_FloatInPlace"
 
 var _ResizingType_index = [...]uint8{0, 20, 44, 69, 94}
 
 func (i ResizingType) String() string {
-	if i < 0 || i >= ResizingType(len(_ResizingType_index)-1) {
-		return fmt.Sprintf("ResizingType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_ResizingType_index)-1 {
+		return "ResizingType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _ResizingType_name[_ResizingType_index[i]:_ResizingType_index[i+1]]
+	return _ResizingType_name[_ResizingType_index[idx]:_ResizingType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[LayerListExpandedType_Collapsed-0]
+	_ = x[LayerListExpandedType_ExpandedTemp-1]
+	_ = x[LayerListExpandedType_Expanded-2]
 }
 
 const _LayerListExpandedType_name = "LayerListExpandedType_CollapsedLayerListExpandedType_ExpandedTempLayerListExpandedType_Expanded"
@@ -20,10 +39,20 @@ const _LayerListExpandedType_name = "LayerListExpandedType_CollapsedLayerListExp
 var _LayerListExpandedType_index = [...]uint8{0, 31, 65, 95}
 
 func (i LayerListExpandedType) String() string {
-	if i < 0 || i >= LayerListExpandedType(len(_LayerListExpandedType_index)-1) {
-		return fmt.Sprintf("LayerListExpandedType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_LayerListExpandedType_index)-1 {
+		return "LayerListExpandedType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _LayerListExpandedType_name[_LayerListExpandedType_index[i]:_LayerListExpandedType_index[i+1]]
+	return _LayerListExpandedType_name[_LayerListExpandedType_index[idx]:_LayerListExpandedType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BorderPosition_Center-0]
+	_ = x[BorderPosition_Inside-1]
+	_ = x[BorderPosition_Outside-2]
+	_ = x[BorderPosition_Both-3]
 }
 
 const _BorderPosition_name = "BorderPosition_CenterBorderPosition_InsideBorderPosition_OutsideBorderPosition_Both"
@@ -31,10 +60,19 @@ const _BorderPosition_name = "BorderPosition_CenterBorderPosition_InsideBorderPo
 var _BorderPosition_index = [...]uint8{0, 21, 42, 64, 83}
 
 func (i BorderPosition) String() string {
-	if i < 0 || i >= BorderPosition(len(_BorderPosition_index)-1) {
-		return fmt.Sprintf("BorderPosition(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_BorderPosition_index)-1 {
+		return "BorderPosition(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _BorderPosition_name[_BorderPosition_index[i]:_BorderPosition_index[i+1]]
+	return _BorderPosition_name[_BorderPosition_index[idx]:_BorderPosition_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BorderLineCapStyle_Butt-0]
+	_ = x[BorderLineCapStyle_Round-1]
+	_ = x[BorderLineCapStyle_Square-2]
 }
 
 const _BorderLineCapStyle_name = "BorderLineCapStyle_ButtBorderLineCapStyle_RoundBorderLineCapStyle_Square"
@@ -42,10 +80,19 @@ const _BorderLineCapStyle_name = "BorderLineCapStyle_ButtBorderLineCapStyle_Roun
 var _BorderLineCapStyle_index = [...]uint8{0, 23, 47, 72}
 
 func (i BorderLineCapStyle) String() string {
-	if i < 0 || i >= BorderLineCapStyle(len(_BorderLineCapStyle_index)-1) {
-		return fmt.Sprintf("BorderLineCapStyle(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_BorderLineCapStyle_index)-1 {
+		return "BorderLineCapStyle(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _BorderLineCapStyle_name[_BorderLineCapStyle_index[i]:_BorderLineCapStyle_index[i+1]]
+	return _BorderLineCapStyle_name[_BorderLineCapStyle_index[idx]:_BorderLineCapStyle_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BorderLineJoinStyle_Miter-0]
+	_ = x[BorderLineJoinStyle_Round-1]
+	_ = x[BorderLineJoinStyle_Bevel-2]
 }
 
 const _BorderLineJoinStyle_name = "BorderLineJoinStyle_MiterBorderLineJoinStyle_RoundBorderLineJoinStyle_Bevel"
@@ -53,10 +100,20 @@ const _BorderLineJoinStyle_name = "BorderLineJoinStyle_MiterBorderLineJoinStyle_
 var _BorderLineJoinStyle_index = [...]uint8{0, 25, 50, 75}
 
 func (i BorderLineJoinStyle) String() string {
-	if i < 0 || i >= BorderLineJoinStyle(len(_BorderLineJoinStyle_index)-1) {
-		return fmt.Sprintf("BorderLineJoinStyle(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_BorderLineJoinStyle_index)-1 {
+		return "BorderLineJoinStyle(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _BorderLineJoinStyle_name[_BorderLineJoinStyle_index[i]:_BorderLineJoinStyle_index[i+1]]
+	return _BorderLineJoinStyle_name[_BorderLineJoinStyle_index[idx]:_BorderLineJoinStyle_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[FillType_Solid-0]
+	_ = x[FillType_Gradient-1]
+	_ = x[FillType_Pattern-2]
+	_ = x[FillType_Noise-3]
 }
 
 const _FillType_name = "FillType_SolidFillType_GradientFillType_PatternFillType_Noise"
@@ -64,10 +121,20 @@ const _FillType_name = "FillType_SolidFillType_GradientFillType_PatternFillType_
 var _FillType_index = [...]uint8{0, 14, 31, 47, 61}
 
 func (i FillType) String() string {
-	if i < 0 || i >= FillType(len(_FillType_index)-1) {
-		return fmt.Sprintf("FillType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_FillType_index)-1 {
+		return "FillType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _FillType_name[_FillType_index[i]:_FillType_index[i+1]]
+	return _FillType_name[_FillType_index[idx]:_FillType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PatternFillType_Tile-0]
+	_ = x[PatternFillType_Fill-1]
+	_ = x[PatternFillType_Stretch-2]
+	_ = x[PatternFillType_Fit-3]
 }
 
 const _PatternFillType_name = "PatternFillType_TilePatternFillType_FillPatternFillType_StretchPatternFillType_Fit"
@@ -75,10 +142,32 @@ const _PatternFillType_name = "PatternFillType_TilePatternFillType_FillPatternFi
 var _PatternFillType_index = [...]uint8{0, 20, 40, 63, 82}
 
 func (i PatternFillType) String() string {
-	if i < 0 || i >= PatternFillType(len(_PatternFillType_index)-1) {
-		return fmt.Sprintf("PatternFillType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_PatternFillType_index)-1 {
+		return "PatternFillType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _PatternFillType_name[_PatternFillType_index[i]:_PatternFillType_index[i+1]]
+	return _PatternFillType_name[_PatternFillType_index[idx]:_PatternFillType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BlendMode_0-0]
+	_ = x[BlendMode_1-1]
+	_ = x[BlendMode_2-2]
+	_ = x[BlendMode_3-3]
+	_ = x[BlendMode_4-4]
+	_ = x[BlendMode_5-5]
+	_ = x[BlendMode_6-6]
+	_ = x[BlendMode_7-7]
+	_ = x[BlendMode_8-8]
+	_ = x[BlendMode_9-9]
+	_ = x[BlendMode_10-10]
+	_ = x[BlendMode_11-11]
+	_ = x[BlendMode_12-12]
+	_ = x[BlendMode_13-13]
+	_ = x[BlendMode_14-14]
+	_ = x[BlendMode_15-15]
 }
 
 const _BlendMode_name = "BlendMode_0BlendMode_1BlendMode_2BlendMode_3BlendMode_4BlendMode_5BlendMode_6BlendMode_7BlendMode_8BlendMode_9BlendMode_10BlendMode_11BlendMode_12BlendMode_13BlendMode_14BlendMode_15"
@@ -86,10 +175,20 @@ const _BlendMode_name = "BlendMode_0BlendMode_1BlendMode_2BlendMode_3BlendMode_4
 var _BlendMode_index = [...]uint8{0, 11, 22, 33, 44, 55, 66, 77, 88, 99, 110, 122, 134, 146, 158, 170, 182}
 
 func (i BlendMode) String() string {
-	if i < 0 || i >= BlendMode(len(_BlendMode_index)-1) {
-		return fmt.Sprintf("BlendMode(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_BlendMode_index)-1 {
+		return "BlendMode(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _BlendMode_name[_BlendMode_index[i]:_BlendMode_index[i+1]]
+	return _BlendMode_name[_BlendMode_index[idx]:_BlendMode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[LineDecorationType_None-0]
+	_ = x[LineDecorationType_OpenArrow-1]
+	_ = x[LineDecorationType_ClosedArrow-2]
+	_ = x[LineDecorationType_Bar-3]
 }
 
 const _LineDecorationType_name = "LineDecorationType_NoneLineDecorationType_OpenArrowLineDecorationType_ClosedArrowLineDecorationType_Bar"
@@ -97,10 +196,21 @@ const _LineDecorationType_name = "LineDecorationType_NoneLineDecorationType_Open
 var _LineDecorationType_index = [...]uint8{0, 23, 51, 81, 103}
 
 func (i LineDecorationType) String() string {
-	if i < 0 || i >= LineDecorationType(len(_LineDecorationType_index)-1) {
-		return fmt.Sprintf("LineDecorationType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_LineDecorationType_index)-1 {
+		return "LineDecorationType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _LineDecorationType_name[_LineDecorationType_index[i]:_LineDecorationType_index[i+1]]
+	return _LineDecorationType_name[_LineDecorationType_index[idx]:_LineDecorationType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BooleanOperation_None - -1]
+	_ = x[BooleanOperation_Union-1]
+	_ = x[BooleanOperation_Subtract-2]
+	_ = x[BooleanOperation_Intersect-3]
+	_ = x[BooleanOperation_Difference-4]
 }
 
 const (
@@ -109,7 +219,6 @@ const (
 )
 
 var (
-	_BooleanOperationType_index_0 = [...]uint8{0, 21}
 	_BooleanOperationType_index_1 = [...]uint8{0, 22, 47, 73, 100}
 )
 
@@ -121,17 +230,68 @@ func (i BooleanOperationType) String() string {
 		i -= 1
 		return _BooleanOperationType_name_1[_BooleanOperationType_index_1[i]:_BooleanOperationType_index_1[i+1]]
 	default:
-		return fmt.Sprintf("BooleanOperationType(%d)", i)
+		return "BooleanOperationType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
 }
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[CurveMode_None-0]
+	_ = x[CurveMode_Straight-1]
+	_ = x[CurveMode_Mirrored-2]
+	_ = x[CurveMode_Disconnected-3]
+	_ = x[CurveMode_Asymmetric-4]
+}
 
 const _CurveMode_name = "CurveMode_NoneCurveMode_StraightCurveMode_MirroredCurveMode_DisconnectedCurveMode_Asymmetric"
 
 var _CurveMode_index = [...]uint8{0, 14, 32, 50, 72, 92}
 
 func (i CurveMode) String() string {
-	if i < 0 || i >= CurveMode(len(_CurveMode_index)-1) {
-		return fmt.Sprintf("CurveMode(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_CurveMode_index)-1 {
+		return "CurveMode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _CurveMode_name[_CurveMode_index[idx]:_CurveMode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[TextTransform_None-0]
+	_ = x[TextTransform_Uppercase-1]
+	_ = x[TextTransform_Lowercase-2]
+}
+
+const _TextTransform_name = "TextTransform_NoneTextTransform_UppercaseTextTransform_Lowercase"
+
+var _TextTransform_index = [...]uint8{0, 18, 41, 64}
+
+func (i TextTransform) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_TextTransform_index)-1 {
+		return "TextTransform(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _TextTransform_name[_TextTransform_index[idx]:_TextTransform_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[TextBehaviour_AutoWidth-0]
+	_ = x[TextBehaviour_AutoHeight-1]
+	_ = x[TextBehaviour_Fixed-2]
+}
+
+const _TextBehaviour_name = "TextBehaviour_AutoWidthTextBehaviour_AutoHeightTextBehaviour_Fixed"
+
+var _TextBehaviour_index = [...]uint8{0, 23, 47, 66}
+
+func (i TextBehaviour) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_TextBehaviour_index)-1 {
+		return "TextBehaviour(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _CurveMode_name[_CurveMode_index[i]:_CurveMode_index[i+1]]
+	return _TextBehaviour_name[_TextBehaviour_index[idx]:_TextBehaviour_index[idx+1]]
 }
@@ -0,0 +1,137 @@
+package sketch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseError reports which entry of a sketch file failed to decode,
+// and where in that entry the failure was found
+type ParseError struct {
+	// Entry is the name of the archive entry, like `pages/<id>.json`
+	Entry string
+	// Pointer is the RFC 6901 JSON pointer of the value that failed
+	// to decode, empty when the entry is not valid JSON
+	Pointer string
+	// Offset is the byte offset of a JSON syntax error in the entry
+	Offset int64
+	// ObjectID is the do_objectID of the innermost layer containing
+	// the failure, when known
+	ObjectID string
+	Err      error
+
+	// layer is the pointer of the innermost layer containing the failure
+	layer string
+}
+
+func (e *ParseError) Error() string {
+	msg := e.Entry
+	if e.Pointer != "" {
+		msg += " at " + e.Pointer
+	}
+	if e.Offset > 0 {
+		msg += " offset " + strconv.FormatInt(e.Offset, 10)
+	}
+	if e.ObjectID != "" {
+		msg += " (layer " + e.ObjectID + ")"
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError turns an error returned while decoding entry
+// into a *ParseError, resolving the path recorded by pathError
+func newParseError(entry string, err error) *ParseError {
+	pe := &ParseError{Entry: entry}
+
+	var p *pathError
+	for errors.As(err, &p) {
+		if p.key != "" {
+			pe.Pointer += "/" + escapePointer(p.key)
+		}
+		if p.objectID != "" {
+			pe.ObjectID = p.objectID
+		}
+		if p.layer {
+			pe.layer = pe.Pointer
+		}
+		err = p.err
+	}
+
+	var syntax *json.SyntaxError
+	if pe.Pointer == "" && errors.As(err, &syntax) {
+		pe.Offset = syntax.Offset
+	}
+
+	pe.Err = err
+	return pe
+}
+
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// pathError records the object key or array index at which
+// decoding failed, so the full path can be rebuilt from the chain
+type pathError struct {
+	key      string
+	objectID string
+	err      error
+
+	// layer is set when key is the index of a layer in Layers
+	layer bool
+}
+
+func (e *pathError) Error() string {
+	return fmt.Sprintf("%s: %v", e.key, e.err)
+}
+
+func (e *pathError) Unwrap() error {
+	return e.err
+}
+
+// decodeValue decodes raw into a new value of type t, descending
+// into arrays and maps to report the failing element
+func decodeValue(raw json.RawMessage, t reflect.Type) error {
+	v := reflect.New(t)
+	err := json.Unmarshal(raw, v.Interface())
+	if _, ok := v.Interface().(json.Unmarshaler); err == nil || ok {
+		// custom decoders report their own path
+		return err
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return err
+		}
+		elems := []json.RawMessage{}
+		if json.Unmarshal(raw, &elems) != nil {
+			return err
+		}
+		for i, elem := range elems {
+			if eerr := decodeValue(elem, t.Elem()); eerr != nil {
+				return &pathError{key: strconv.Itoa(i), err: eerr}
+			}
+		}
+	case reflect.Map:
+		elems := map[string]json.RawMessage{}
+		if json.Unmarshal(raw, &elems) != nil {
+			return err
+		}
+		for key, elem := range elems {
+			if eerr := decodeValue(elem, t.Elem()); eerr != nil {
+				return &pathError{key: key, err: eerr}
+			}
+		}
+	}
+	return err
+}
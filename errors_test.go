@@ -0,0 +1,56 @@
+package sketch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseErrorNestedLayer(t *testing.T) {
+	fsys := pageFS(`{"_class":"artboard","do_objectID":"A1","frame":{},"layers":[` +
+		`{"_class":"group","do_objectID":"G1","frame":{},"layers":[` +
+		`{"_class":"text","do_objectID":"T1","frame":{},"style":{"fills":[{"_class":"fill","color":"red"}]}}]}]}`)
+
+	_, err := ParseFS(fsys)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %T %v, want a *ParseError", err, err)
+	}
+	if perr.Entry != "pages/P1.json" || perr.Pointer != "/layers/0/layers/0/layers/0/style/fills/0/color" || perr.ObjectID != "T1" || perr.Offset != 0 {
+		t.Fatalf("got %+v", perr)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("got %v, want the *json.UnmarshalTypeError", perr.Err)
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "pages/P1.json at /layers/0/layers/0/layers/0/style/fills/0/color (layer T1): ") {
+		t.Fatalf("got message %q", msg)
+	}
+}
+
+func TestParseErrorSyntax(t *testing.T) {
+	fsys := minimalFS()
+	fsys["pages/P1.json"].Data = []byte(`{"_class":"page","layers":[}`)
+
+	_, err := ParseFS(fsys)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %T %v, want a *ParseError", err, err)
+	}
+	var syntax *json.SyntaxError
+	if perr.Entry != "pages/P1.json" || perr.Pointer != "" || perr.Offset != 28 || !errors.As(err, &syntax) {
+		t.Fatalf("got %+v", perr)
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "pages/P1.json offset 28: ") {
+		t.Fatalf("got message %q", msg)
+	}
+}
+
+func TestParseErrorEscapesPointer(t *testing.T) {
+	err := newParseError("user.json", &pathError{key: "a/b~c", err: &pathError{key: "0", err: errors.New("bad")}})
+	if err.Pointer != "/a~1b~0c/0" {
+		t.Fatalf("got pointer %q", err.Pointer)
+	}
+}
@@ -0,0 +1,207 @@
+package sketch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// explodedFiles and explodedDirs are the entries of an exploded
+// sketch file, Explode replaces the directories and Implode packs
+// nothing else
+var (
+	explodedFiles = []string{"document.json", "meta.json", "user.json"}
+	explodedDirs  = []string{"pages", "images", "previews"}
+)
+
+// Explode extracts the sketch file at src into dir, writing the JSON
+// entries pretty printed with sorted keys so they diff well. The pages,
+// images and previews directories of a previous Explode are removed so
+// deleted pages do not linger, other files of dir are left in place.
+// src may be an exploded directory, but not dir itself
+func Explode(src, dir string) error {
+	if same, err := sameDir(src, dir); err != nil || same {
+		if err == nil {
+			err = errors.Errorf("explode %s into itself", src)
+		}
+		return err
+	}
+
+	fsys, closer, err := openFS(src)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	if err := checkEntries(fsys, Limits{}); err != nil {
+		return err
+	}
+
+	for _, name := range explodedDirs {
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if path.Ext(name) == ".json" {
+			if b, err = indentJSON(b); err != nil {
+				return newParseError(name, err)
+			}
+		}
+
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, b, 0o644)
+	})
+}
+
+// sameDir reports whether src is the existing directory dir
+func sameDir(src, dir string) (bool, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return false, err
+	}
+	dirInfo, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(srcInfo, dirInfo), nil
+}
+
+// indentJSON re-encodes b with sorted keys and an indent,
+// keeping numbers as written
+func indentJSON(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Implode packs a directory written by Explode into the sketch file dst,
+// compacting the JSON entries again. Only the entries of a sketch file
+// are packed, other files like a README or a .git directory and hidden
+// files are left out
+func Implode(dir, dst string) error {
+	fsys := os.DirFS(dir)
+
+	names := []string{}
+	for _, name := range explodedFiles {
+		if _, err := fs.Stat(fsys, name); err == nil {
+			names = append(names, name)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	for _, root := range explodedDirs {
+		err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() {
+				names = append(names, name)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := entryRank(names[i]), entryRank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if err := implode(fsys, names, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func implode(fsys fs.FS, names []string, out *os.File) error {
+	zw := zip.NewWriter(out)
+	for _, name := range names {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if path.Ext(name) == ".json" {
+			buf := &bytes.Buffer{}
+			if err := json.Compact(buf, b); err != nil {
+				return newParseError(name, err)
+			}
+			b = buf.Bytes()
+		}
+
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// entryRank orders the entries of an archive the way WriteTo does
+func entryRank(name string) int {
+	switch {
+	case name == "document.json":
+		return 0
+	case name == "meta.json":
+		return 1
+	case name == "user.json":
+		return 2
+	case strings.HasPrefix(name, "pages/"):
+		return 3
+	}
+	return 4
+}
@@ -0,0 +1,205 @@
+package sketch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+)
+
+// explodeMinimal saves minimalFS with an image to dir and explodes it
+// into dir/out, returning the file saved
+func explodeMinimal(t *testing.T, dir string) (*File, string) {
+	t.Helper()
+	fsys := minimalFS()
+	fsys["images/a.png"] = &fstest.MapFile{Data: []byte("png")}
+	f, err := ParseFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(dir, "src.sketch")
+	if err := f.Save(src); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	if err := Explode(src, out); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = Parse(src); err != nil {
+		t.Fatal(err)
+	}
+	return f, out
+}
+
+func TestImplodeRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	f, out := explodeMinimal(t, dir)
+
+	// files of the repository the document is kept in
+	for name, data := range map[string]string{
+		".git/HEAD":       "ref: refs/heads/main\n",
+		"README.md":       "# Design\n",
+		".DS_Store":       "",
+		"pages/.DS_Store": "",
+	} {
+		name = filepath.Join(out, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(dir, "dst.sketch")
+	if err := Implode(out, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	names := []string{}
+	for _, zf := range zr.File {
+		names = append(names, zf.Name)
+	}
+	want := []string{"document.json", "meta.json", "user.json", "pages/P1.json", "images/a.png"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("got entries %q, want %q", names, want)
+	}
+
+	got, err := Parse(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Pages, f.Pages) || !reflect.DeepEqual(got.Images, f.Images) || got.Document.DoObjectID != "D1" {
+		t.Fatalf("got %+v, want %+v", got, f)
+	}
+}
+
+// sortedKeys reports whether the keys of every object of the JSON
+// read by dec are in order
+func sortedKeys(dec *json.Decoder) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		last := ""
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return false, err
+			}
+			key := tok.(string)
+			if key < last {
+				return false, nil
+			}
+			last = key
+			if ok, err := sortedKeys(dec); !ok || err != nil {
+				return ok, err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for dec.More() {
+			if ok, err := sortedKeys(dec); !ok || err != nil {
+				return ok, err
+			}
+		}
+		_, err = dec.Token()
+	}
+	return err == nil, err
+}
+
+func TestExplodeSortsKeys(t *testing.T) {
+	_, out := explodeMinimal(t, t.TempDir())
+
+	for _, name := range []string{"document.json", "meta.json", "pages/P1.json"} {
+		b, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), "\n  \"") {
+			t.Errorf("%s is not indented:\n%s", name, b)
+		}
+		ok, err := sortedKeys(json.NewDecoder(bytes.NewReader(b)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("%s keys are not sorted:\n%s", name, b)
+		}
+	}
+}
+
+func TestExplodeIntoItself(t *testing.T) {
+	_, out := explodeMinimal(t, t.TempDir())
+
+	if err := Explode(out, out); err == nil {
+		t.Fatal("exploded a directory into itself")
+	}
+	if err := Explode(out, out+string(filepath.Separator)+"."); err == nil {
+		t.Fatal("exploded a directory into itself")
+	}
+	got, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Pages) != 1 {
+		t.Fatalf("got %d pages after exploding into itself, want 1", len(got.Pages))
+	}
+}
+
+func TestExplodeRemovesDeletedPages(t *testing.T) {
+	fsys := minimalFS()
+	fsys["pages/P2.json"] = &fstest.MapFile{Data: []byte(`{"_class":"page","do_objectID":"P2","name":"Page 2","layers":[]}`)}
+	f, err := ParseFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Pages) != 1 {
+		t.Fatalf("got %d pages, want the referenced page only", len(f.Pages))
+	}
+	if len(f.Warnings) != 1 || !errors.Is(f.Warnings[0], ErrUnreferencedPage) || f.Warnings[0].Entry != "pages/P2.json" {
+		t.Fatalf("got warnings %v, want pages/P2.json unreferenced", f.Warnings)
+	}
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "two.sketch")
+	f.Pages = append(f.Pages, &Page{Class: "page", DoObjectID: "P2", Name: "Page 2"})
+	if err := f.Save(src); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	if err := Explode(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Pages = f.Pages[:1]
+	if err := f.Save(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := Explode(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Pages) != 1 || len(got.Warnings) != 0 {
+		t.Fatalf("got %d pages and warnings %v after re-exploding, want 1 page", len(got.Pages), got.Warnings)
+	}
+}
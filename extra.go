@@ -0,0 +1,892 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Extra holds the JSON properties of an object that its Go type does not
+// declare, so that decoding and encoding a document does not lose data
+// written by newer versions of Sketch
+type Extra struct {
+	Fields map[string]json.RawMessage
+
+	// present records which declared properties were in the decoded JSON,
+	// so encoding writes back the same set of properties. It is nil for
+	// values that were not decoded
+	present map[string]bool
+}
+
+// jsonField is a property declared by a struct type
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	// key is the encoded name
+	key []byte
+}
+
+// jsonFieldSet is the cached list of the properties of a struct type
+type jsonFieldSet struct {
+	list   []jsonField
+	byName map[string]jsonField
+}
+
+var jsonFieldCache sync.Map // map[reflect.Type]*jsonFieldSet
+
+// jsonFields lists the JSON properties declared by the struct type t,
+// including those of embedded structs. Like encoding/json, a property
+// declared at several depths is the shallowest one, and a property
+// declared twice at the same depth is ignored
+func jsonFields(t reflect.Type) []jsonField {
+	return jsonFieldsOf(t).list
+}
+
+func jsonFieldsOf(t reflect.Type) *jsonFieldSet {
+	if f, ok := jsonFieldCache.Load(t); ok {
+		return f.(*jsonFieldSet)
+	}
+
+	fields := []jsonField{}
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			idx := append(append([]int{}, index...), i)
+
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+
+			if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+				walk(sf.Type, idx)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			key, _ := json.Marshal(name)
+			fields = append(fields, jsonField{
+				name:      name,
+				index:     idx,
+				omitEmpty: slices.Contains(strings.Split(opts, ","), "omitempty"),
+				key:       key,
+			})
+		}
+	}
+	walk(t, nil)
+
+	best := map[string][]jsonField{}
+	for _, f := range fields {
+		b := best[f.name]
+		switch {
+		case len(b) == 0 || len(f.index) < len(b[0].index):
+			best[f.name] = []jsonField{f}
+		case len(f.index) == len(b[0].index):
+			best[f.name] = append(b, f)
+		}
+	}
+
+	set := &jsonFieldSet{byName: map[string]jsonField{}}
+	for _, f := range fields {
+		if b := best[f.name]; len(b) == 1 && slices.Equal(b[0].index, f.index) {
+			set.list = append(set.list, f)
+			set.byName[f.name] = f
+		}
+	}
+
+	jsonFieldCache.Store(t, set)
+	return set
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unmarshalExtra decodes data into v, a pointer to a struct without its own
+// UnmarshalJSON, and collects the undeclared properties into extra.
+// The properties are split in a single scan and each is decoded on its
+// own, handing nested objects their bytes without decoding them again
+func unmarshalExtra(data []byte, v interface{}, extra *Extra) error {
+	type prop struct {
+		name  string
+		value []byte
+	}
+	props := []prop{}
+	if !scanObject(data, func(name string, value []byte) bool {
+		props = append(props, prop{name, value})
+		return true
+	}) {
+		return json.Unmarshal(data, v)
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	fields := jsonFieldsOf(rv.Type()).byName
+	present := map[string]bool{}
+	var extraFields map[string]json.RawMessage
+	for _, p := range props {
+		f, ok := fields[p.name]
+		if !ok {
+			if extraFields == nil {
+				extraFields = map[string]json.RawMessage{}
+			}
+			extraFields[p.name] = append(json.RawMessage(nil), p.value...)
+			continue
+		}
+
+		present[p.name] = true
+		fv := rv.FieldByIndex(f.index)
+		if err := decodeField(p.value, fv); err != nil {
+			return &pathError{key: p.name, err: err}
+		}
+	}
+
+	*extra = Extra{Fields: extraFields, present: present}
+	return nil
+}
+
+// decodeField decodes raw into the struct field fv, calling the
+// UnmarshalJSON of its type directly when it has one. Custom decoders
+// report their own path, for other types the failing element is located
+func decodeField(raw []byte, fv reflect.Value) error {
+	if string(raw) != "null" {
+		switch {
+		case fv.Kind() == reflect.Pointer && fv.Type().Implements(unmarshalerType):
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			return fv.Interface().(json.Unmarshaler).UnmarshalJSON(raw)
+		case fv.Kind() != reflect.Pointer && fv.Addr().Type().Implements(unmarshalerType):
+			return fv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(raw)
+		}
+	}
+	err := json.Unmarshal(raw, fv.Addr().Interface())
+	if err != nil {
+		if ferr := decodeValue(raw, fv.Type()); ferr != nil {
+			return ferr
+		}
+	}
+	return err
+}
+
+// marshalExtra encodes v, a struct without its own MarshalJSON, merging in
+// the undeclared properties held by extra. Declared properties keep the
+// presence they had when decoded: zero values that were missing stay
+// missing and values that were present are written even if omitempty.
+// The object is written property by property, so the encoding of nested
+// values is not decoded again
+func marshalExtra(v interface{}, extra Extra) ([]byte, error) {
+	if extra.present == nil && len(extra.Fields) == 0 {
+		return json.Marshal(v)
+	}
+
+	rv := reflect.ValueOf(v)
+	set := jsonFieldsOf(rv.Type())
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	writeKey := func(key []byte) {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+	}
+
+	var written map[string]bool
+	for _, f := range set.list {
+		fv := rv.FieldByIndex(f.index)
+		write := !f.omitEmpty || !isEmptyValue(fv)
+		if extra.present != nil {
+			write = extra.present[f.name] || (write && !fv.IsZero())
+		}
+		if !write {
+			continue
+		}
+
+		raw, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		writeKey(f.key)
+		buf.Write(raw)
+		if len(extra.Fields) > 0 {
+			if written == nil {
+				written = map[string]bool{}
+			}
+			written[f.name] = true
+		}
+	}
+
+	names := make([]string, 0, len(extra.Fields))
+	for name := range extra.Fields {
+		if !written[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		writeKey(key)
+		buf.Write(extra.Fields[name])
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// isEmptyValue reports whether encoding/json omits v from a field with omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func (d *Document) UnmarshalJSON(data []byte) error {
+	type plain Document
+	return unmarshalExtra(data, (*plain)(d), &d.Extra)
+}
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	type plain Document
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (p *Page) UnmarshalJSON(data []byte) error {
+	type plain Page
+	return unmarshalExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p Page) MarshalJSON() ([]byte, error) {
+	type plain Page
+	return marshalExtra(plain(p), p.Extra)
+}
+
+func (m *Meta) UnmarshalJSON(data []byte) error {
+	type plain Meta
+	return unmarshalExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m Meta) MarshalJSON() ([]byte, error) {
+	type plain Meta
+	return marshalExtra(plain(m), m.Extra)
+}
+
+func (m *MetaCreated) UnmarshalJSON(data []byte) error {
+	type plain MetaCreated
+	return unmarshalExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m MetaCreated) MarshalJSON() ([]byte, error) {
+	type plain MetaCreated
+	return marshalExtra(plain(m), m.Extra)
+}
+
+func (p *PageMeta) UnmarshalJSON(data []byte) error {
+	type plain PageMeta
+	return unmarshalExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p PageMeta) MarshalJSON() ([]byte, error) {
+	type plain PageMeta
+	return marshalExtra(plain(p), p.Extra)
+}
+
+func (a *ArtboardMeta) UnmarshalJSON(data []byte) error {
+	type plain ArtboardMeta
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a ArtboardMeta) MarshalJSON() ([]byte, error) {
+	type plain ArtboardMeta
+	return marshalExtra(plain(a), a.Extra)
+}
+
+func (d *DocumentUserState) UnmarshalJSON(data []byte) error {
+	type plain DocumentUserState
+	return unmarshalExtra(data, (*plain)(d), &d.Extra)
+}
+
+func (d DocumentUserState) MarshalJSON() ([]byte, error) {
+	type plain DocumentUserState
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (p *PageUserState) UnmarshalJSON(data []byte) error {
+	type plain PageUserState
+	return unmarshalExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p PageUserState) MarshalJSON() ([]byte, error) {
+	type plain PageUserState
+	return marshalExtra(plain(p), p.Extra)
+}
+
+func (l *LayoutGrid) UnmarshalJSON(data []byte) error {
+	type plain LayoutGrid
+	return unmarshalExtra(data, (*plain)(l), &l.Extra)
+}
+
+func (l LayoutGrid) MarshalJSON() ([]byte, error) {
+	type plain LayoutGrid
+	return marshalExtra(plain(l), l.Extra)
+}
+
+func (e *ExportFormat) UnmarshalJSON(data []byte) error {
+	type plain ExportFormat
+	return unmarshalExtra(data, (*plain)(e), &e.Extra)
+}
+
+func (e ExportFormat) MarshalJSON() ([]byte, error) {
+	type plain ExportFormat
+	return marshalExtra(plain(e), e.Extra)
+}
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	type plain Color
+	return unmarshalExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c Color) MarshalJSON() ([]byte, error) {
+	type plain Color
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (r *RulerData) UnmarshalJSON(data []byte) error {
+	type plain RulerData
+	return unmarshalExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r RulerData) MarshalJSON() ([]byte, error) {
+	type plain RulerData
+	return marshalExtra(plain(r), r.Extra)
+}
+
+func (g *GraphicContextSettings) UnmarshalJSON(data []byte) error {
+	type plain GraphicContextSettings
+	return unmarshalExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (g GraphicContextSettings) MarshalJSON() ([]byte, error) {
+	type plain GraphicContextSettings
+	return marshalExtra(plain(g), g.Extra)
+}
+
+func (s *Shadow) UnmarshalJSON(data []byte) error {
+	type plain Shadow
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s Shadow) MarshalJSON() ([]byte, error) {
+	type plain Shadow
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (c *ColorControls) UnmarshalJSON(data []byte) error {
+	type plain ColorControls
+	return unmarshalExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c ColorControls) MarshalJSON() ([]byte, error) {
+	type plain ColorControls
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (f *Fill) UnmarshalJSON(data []byte) error {
+	type plain Fill
+	return unmarshalExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (f Fill) MarshalJSON() ([]byte, error) {
+	type plain Fill
+	return marshalExtra(plain(f), f.Extra)
+}
+
+func (b *Border) UnmarshalJSON(data []byte) error {
+	type plain Border
+	return unmarshalExtra(data, (*plain)(b), &b.Extra)
+}
+
+func (b Border) MarshalJSON() ([]byte, error) {
+	type plain Border
+	return marshalExtra(plain(b), b.Extra)
+}
+
+func (g *GradientStop) UnmarshalJSON(data []byte) error {
+	type plain GradientStop
+	return unmarshalExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (g GradientStop) MarshalJSON() ([]byte, error) {
+	type plain GradientStop
+	return marshalExtra(plain(g), g.Extra)
+}
+
+func (r *Rect) UnmarshalJSON(data []byte) error {
+	type plain Rect
+	return unmarshalExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r Rect) MarshalJSON() ([]byte, error) {
+	type plain Rect
+	return marshalExtra(plain(r), r.Extra)
+}
+
+func (c *CurvePoint) UnmarshalJSON(data []byte) error {
+	type plain CurvePoint
+	return unmarshalExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c CurvePoint) MarshalJSON() ([]byte, error) {
+	type plain CurvePoint
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (b *BorderOptions) UnmarshalJSON(data []byte) error {
+	type plain BorderOptions
+	return unmarshalExtra(data, (*plain)(b), &b.Extra)
+}
+
+func (b BorderOptions) MarshalJSON() ([]byte, error) {
+	type plain BorderOptions
+	return marshalExtra(plain(b), b.Extra)
+}
+
+func (g *Gradient) UnmarshalJSON(data []byte) error {
+	type plain Gradient
+	return unmarshalExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (g Gradient) MarshalJSON() ([]byte, error) {
+	type plain Gradient
+	return marshalExtra(plain(g), g.Extra)
+}
+
+func (t *TextStyle) UnmarshalJSON(data []byte) error {
+	type plain TextStyle
+	return unmarshalExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (t TextStyle) MarshalJSON() ([]byte, error) {
+	type plain TextStyle
+	return marshalExtra(plain(t), t.Extra)
+}
+
+func (s *Style) UnmarshalJSON(data []byte) error {
+	type plain Style
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s Style) MarshalJSON() ([]byte, error) {
+	type plain Style
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (s *SharedStyle) UnmarshalJSON(data []byte) error {
+	type plain SharedStyle
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SharedStyle) MarshalJSON() ([]byte, error) {
+	type plain SharedStyle
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (s *SharedStyleContainer) UnmarshalJSON(data []byte) error {
+	type plain SharedStyleContainer
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SharedStyleContainer) MarshalJSON() ([]byte, error) {
+	type plain SharedStyleContainer
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (s *SharedSymbolContainer) UnmarshalJSON(data []byte) error {
+	type plain SharedSymbolContainer
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SharedSymbolContainer) MarshalJSON() ([]byte, error) {
+	type plain SharedSymbolContainer
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (s *SharedTextStyleContainer) UnmarshalJSON(data []byte) error {
+	type plain SharedTextStyleContainer
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SharedTextStyleContainer) MarshalJSON() ([]byte, error) {
+	type plain SharedTextStyleContainer
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (s *SharedAssetsCollection) UnmarshalJSON(data []byte) error {
+	type plain SharedAssetsCollection
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SharedAssetsCollection) MarshalJSON() ([]byte, error) {
+	type plain SharedAssetsCollection
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (b *Blur) UnmarshalJSON(data []byte) error {
+	type plain Blur
+	return unmarshalExtra(data, (*plain)(b), &b.Extra)
+}
+
+func (b Blur) MarshalJSON() ([]byte, error) {
+	type plain Blur
+	return marshalExtra(plain(b), b.Extra)
+}
+
+func (e *ExportOptions) UnmarshalJSON(data []byte) error {
+	type plain ExportOptions
+	return unmarshalExtra(data, (*plain)(e), &e.Extra)
+}
+
+func (e ExportOptions) MarshalJSON() ([]byte, error) {
+	type plain ExportOptions
+	return marshalExtra(plain(e), e.Extra)
+}
+
+func (p *Path) UnmarshalJSON(data []byte) error {
+	type plain Path
+	return unmarshalExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p Path) MarshalJSON() ([]byte, error) {
+	type plain Path
+	return marshalExtra(plain(p), p.Extra)
+}
+
+func (e *EncodedAttributes) UnmarshalJSON(data []byte) error {
+	type plain EncodedAttributes
+	return unmarshalExtra(data, (*plain)(e), &e.Extra)
+}
+
+func (e EncodedAttributes) MarshalJSON() ([]byte, error) {
+	type plain EncodedAttributes
+	return marshalExtra(plain(e), e.Extra)
+}
+
+func (m *MSJSONFileReference) UnmarshalJSON(data []byte) error {
+	type plain MSJSONFileReference
+	return unmarshalExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m MSJSONFileReference) MarshalJSON() ([]byte, error) {
+	type plain MSJSONFileReference
+	return marshalExtra(plain(m), m.Extra)
+}
+
+func (d *DataString) UnmarshalJSON(data []byte) error {
+	type plain DataString
+	return unmarshalExtra(data, (*plain)(d), &d.Extra)
+}
+
+func (d DataString) MarshalJSON() ([]byte, error) {
+	type plain DataString
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (m *MSAttributedString) UnmarshalJSON(data []byte) error {
+	type plain MSAttributedString
+	return unmarshalExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m MSAttributedString) MarshalJSON() ([]byte, error) {
+	type plain MSAttributedString
+	return marshalExtra(plain(m), m.Extra)
+}
+
+func (a *ArchivedAttributedString) UnmarshalJSON(data []byte) error {
+	type plain ArchivedAttributedString
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a ArchivedAttributedString) MarshalJSON() ([]byte, error) {
+	type plain ArchivedAttributedString
+	return marshalExtra(plain(a), a.Extra)
+}
+
+func (a *AssetsCollection) UnmarshalJSON(data []byte) error {
+	type plain AssetsCollection
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a AssetsCollection) MarshalJSON() ([]byte, error) {
+	type plain AssetsCollection
+	return marshalExtra(plain(a), a.Extra)
+}
+
+func (i *ImageCollection) UnmarshalJSON(data []byte) error {
+	type plain ImageCollection
+	return unmarshalExtra(data, (*plain)(i), &i.Extra)
+}
+
+func (i ImageCollection) MarshalJSON() ([]byte, error) {
+	type plain ImageCollection
+	return marshalExtra(plain(i), i.Extra)
+}
+
+func (g *Group) UnmarshalJSON(data []byte) error {
+	type plain Group
+	return unmarshalExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (g Group) MarshalJSON() ([]byte, error) {
+	type plain Group
+	return marshalExtra(plain(g), g.Extra)
+}
+
+func (s *ShapeGroup) UnmarshalJSON(data []byte) error {
+	type plain ShapeGroup
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s ShapeGroup) MarshalJSON() ([]byte, error) {
+	type plain ShapeGroup
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (a *Artboard) UnmarshalJSON(data []byte) error {
+	type plain Artboard
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a Artboard) MarshalJSON() ([]byte, error) {
+	type plain Artboard
+	return marshalExtra(plain(a), a.Extra)
+}
+
+func (s *SymbolMaster) UnmarshalJSON(data []byte) error {
+	type plain SymbolMaster
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SymbolMaster) MarshalJSON() ([]byte, error) {
+	type plain SymbolMaster
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (s *SymbolInstance) UnmarshalJSON(data []byte) error {
+	type plain SymbolInstance
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s SymbolInstance) MarshalJSON() ([]byte, error) {
+	type plain SymbolInstance
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (r *Rectangle) UnmarshalJSON(data []byte) error {
+	type plain Rectangle
+	return unmarshalExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r Rectangle) MarshalJSON() ([]byte, error) {
+	type plain Rectangle
+	return marshalExtra(plain(r), r.Extra)
+}
+
+func (o *Oval) UnmarshalJSON(data []byte) error {
+	type plain Oval
+	return unmarshalExtra(data, (*plain)(o), &o.Extra)
+}
+
+func (o Oval) MarshalJSON() ([]byte, error) {
+	type plain Oval
+	return marshalExtra(plain(o), o.Extra)
+}
+
+func (s *ShapePath) UnmarshalJSON(data []byte) error {
+	type plain ShapePath
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s ShapePath) MarshalJSON() ([]byte, error) {
+	type plain ShapePath
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (s *Star) UnmarshalJSON(data []byte) error {
+	type plain Star
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s Star) MarshalJSON() ([]byte, error) {
+	type plain Star
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (p *Polygon) UnmarshalJSON(data []byte) error {
+	type plain Polygon
+	return unmarshalExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p Polygon) MarshalJSON() ([]byte, error) {
+	type plain Polygon
+	return marshalExtra(plain(p), p.Extra)
+}
+
+func (t *Triangle) UnmarshalJSON(data []byte) error {
+	type plain Triangle
+	return unmarshalExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (t Triangle) MarshalJSON() ([]byte, error) {
+	type plain Triangle
+	return marshalExtra(plain(t), t.Extra)
+}
+
+func (t *Text) UnmarshalJSON(data []byte) error {
+	type plain Text
+	return unmarshalExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (t Text) MarshalJSON() ([]byte, error) {
+	type plain Text
+	return marshalExtra(plain(t), t.Extra)
+}
+
+func (b *Bitmap) UnmarshalJSON(data []byte) error {
+	type plain Bitmap
+	return unmarshalExtra(data, (*plain)(b), &b.Extra)
+}
+
+func (b Bitmap) MarshalJSON() ([]byte, error) {
+	type plain Bitmap
+	return marshalExtra(plain(b), b.Extra)
+}
+
+func (s *Slice) UnmarshalJSON(data []byte) error {
+	type plain Slice
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s Slice) MarshalJSON() ([]byte, error) {
+	type plain Slice
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (h *Hotspot) UnmarshalJSON(data []byte) error {
+	type plain Hotspot
+	return unmarshalExtra(data, (*plain)(h), &h.Extra)
+}
+
+func (h Hotspot) MarshalJSON() ([]byte, error) {
+	type plain Hotspot
+	return marshalExtra(plain(h), h.Extra)
+}
+
+func (u *UnknownLayer) UnmarshalJSON(data []byte) error {
+	type plain UnknownLayer
+	return unmarshalExtra(data, (*plain)(u), &u.Extra)
+}
+
+func (u UnknownLayer) MarshalJSON() ([]byte, error) {
+	type plain UnknownLayer
+	return marshalExtra(plain(u), u.Extra)
+}
+
+func (s *StringAttribute) UnmarshalJSON(data []byte) error {
+	type plain StringAttribute
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s StringAttribute) MarshalJSON() ([]byte, error) {
+	type plain StringAttribute
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (p *ParagraphStyle) UnmarshalJSON(data []byte) error {
+	type plain ParagraphStyle
+	return unmarshalExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p ParagraphStyle) MarshalJSON() ([]byte, error) {
+	type plain ParagraphStyle
+	return marshalExtra(plain(p), p.Extra)
+}
+
+func (f *FontDescriptor) UnmarshalJSON(data []byte) error {
+	type plain FontDescriptor
+	return unmarshalExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (f FontDescriptor) MarshalJSON() ([]byte, error) {
+	type plain FontDescriptor
+	return marshalExtra(plain(f), f.Extra)
+}
+
+func (f *FontDescriptorAttributes) UnmarshalJSON(data []byte) error {
+	type plain FontDescriptorAttributes
+	return unmarshalExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (f FontDescriptorAttributes) MarshalJSON() ([]byte, error) {
+	type plain FontDescriptorAttributes
+	return marshalExtra(plain(f), f.Extra)
+}
+
+func (f *ForeignSymbol) UnmarshalJSON(data []byte) error {
+	type plain ForeignSymbol
+	return unmarshalExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (f ForeignSymbol) MarshalJSON() ([]byte, error) {
+	type plain ForeignSymbol
+	return marshalExtra(plain(f), f.Extra)
+}
+
+func (f *FlowConnection) UnmarshalJSON(data []byte) error {
+	type plain FlowConnection
+	return unmarshalExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (f FlowConnection) MarshalJSON() ([]byte, error) {
+	type plain FlowConnection
+	return marshalExtra(plain(f), f.Extra)
+}
+
+func (g *GroupLayout) UnmarshalJSON(data []byte) error {
+	type plain GroupLayout
+	return unmarshalExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (g GroupLayout) MarshalJSON() ([]byte, error) {
+	type plain GroupLayout
+	return marshalExtra(plain(g), g.Extra)
+}
+
+func (o *OverrideValue) UnmarshalJSON(data []byte) error {
+	type plain OverrideValue
+	return unmarshalExtra(data, (*plain)(o), &o.Extra)
+}
+
+func (o OverrideValue) MarshalJSON() ([]byte, error) {
+	type plain OverrideValue
+	return marshalExtra(plain(o), o.Extra)
+}
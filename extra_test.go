@@ -0,0 +1,169 @@
+package sketch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// entryValue returns the type an entry of a sketch file decodes into
+func entryValue(name string) interface{} {
+	switch name {
+	case "document.json":
+		return &Document{}
+	case "meta.json":
+		return &Meta{}
+	case "user.json":
+		return &UserState{}
+	}
+	return &Page{}
+}
+
+// assertSameJSON fails unless a and b decode to the same value
+func assertSameJSON(t *testing.T, name string, a, b []byte) {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if !reflect.DeepEqual(x, y) {
+		t.Errorf("%s changed:\n%s\n%s", name, a, b)
+	}
+}
+
+func TestRoundTripUnknownKeys(t *testing.T) {
+	dir := "testdata/unknown-keys"
+	fixture := map[string][]byte{}
+	err := fs.WalkDir(os.DirFS(dir), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		fixture[name], err = fs.ReadFile(os.DirFS(dir), name)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, in := range fixture {
+		v := entryValue(name)
+		if err := json.Unmarshal(in, v); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		out, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assertSameJSON(t, name, in, out)
+	}
+
+	f, err := Parse(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.Pages[0].Layers[0].(*Artboard).Layers[1].(*UnknownLayer); !ok {
+		t.Fatalf("future layer decoded as %T", f.Pages[0].Layers[0].(*Artboard).Layers[1])
+	}
+	buf := &bytes.Buffer{}
+	if _, err := f.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, zf := range zr.File {
+		r, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertSameJSON(t, zf.Name, fixture[zf.Name], out)
+	}
+	if len(zr.File) != len(fixture) {
+		t.Fatalf("wrote %d entries, want %d", len(zr.File), len(fixture))
+	}
+}
+
+// nestedPage is a page of groups nested depth levels deep,
+// each holding text layers with an undeclared property
+func nestedPage(depth int) []byte {
+	text := `{"_class":"text","do_objectID":"T","name":"t","frame":{"_class":"rect","x":1,"y":2,"width":3,"height":4},"unknownKey":{"a":[1,2,3]},"attributedString":{"_class":"attributedString","string":"hello","attributes":[{"_class":"stringAttribute","location":0,"length":5,"attributes":{"kerning":1}}]}}`
+	layer := text
+	for i := 0; i < depth; i++ {
+		layer = fmt.Sprintf(`{"_class":"group","do_objectID":"G%d","name":"g","frame":{"_class":"rect","x":1,"y":2,"width":3,"height":4},"layers":[%s,%s]}`, i, layer, strings.Repeat(text+",", 20)+text)
+	}
+	return []byte(`{"_class":"page","do_objectID":"P","name":"p","layers":[` + layer + `]}`)
+}
+
+// BenchmarkUnmarshalPage compares decoding a page, keeping the
+// undeclared properties, with decoding the same JSON into interface{}
+func BenchmarkUnmarshalPage(b *testing.B) {
+	for _, depth := range []int{1, 10, 40} {
+		data := nestedPage(depth)
+		b.Run(fmt.Sprint("page/", depth), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				p := &Page{}
+				if err := json.Unmarshal(data, p); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprint("interface/", depth), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				var v interface{}
+				if err := json.Unmarshal(data, &v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMarshalPage compares encoding a decoded page, writing back
+// the undeclared properties, with encoding the same JSON from interface{}
+func BenchmarkMarshalPage(b *testing.B) {
+	for _, depth := range []int{1, 10, 40} {
+		data := nestedPage(depth)
+		p := &Page{}
+		var v interface{}
+		if err := json.Unmarshal(data, p); err != nil {
+			b.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprint("page/", depth), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := json.Marshal(p); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprint("interface/", depth), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := json.Marshal(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package sketch
+
+import "strings"
+
+type File struct {
+	Document Document
+	Meta     Meta
+	User     UserState
+
+	// Pages are in document order, nil pages are skipped
+	Pages []*Page
+
+	// Images and Previews hold the content of the images/ and previews/
+	// directories of the archive, keyed by entry name
+	Images   map[string][]byte
+	Previews map[string][]byte
+
+	// Warnings lists the problems skipped over by ParseLenient
+	Warnings []*ParseError
+}
+
+// Page returns the page with the given do_objectID, or nil
+func (f *File) Page(id string) *Page {
+	for _, p := range f.Pages {
+		if p != nil && p.DoObjectID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// PageByName returns the first page with the given name, or nil.
+// Page names are not unique, use Page to look up a specific page
+func (f *File) PageByName(name string) *Page {
+	for _, p := range f.Pages {
+		if p != nil && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// CurrentPage returns the page that was selected when the document was saved
+func (f *File) CurrentPage() *Page {
+	i, err := f.Document.CurrentPageIndex.Int64()
+	if err != nil || i < 0 || i >= int64(len(f.Document.Pages)) {
+		return nil
+	}
+
+	ref := f.Document.Pages[i]
+	if ref == nil {
+		return nil
+	}
+	return f.Page(strings.TrimPrefix(ref.Ref, "pages/"))
+}
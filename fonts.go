@@ -0,0 +1,182 @@
+package sketch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FontUsage is a font used by the text of the document
+type FontUsage struct {
+	// Name is the PostScript name of the font
+	Name  string
+	Sizes []float64
+	// Weight is the CSS weight guessed from the style in the name,
+	// 400 when the name has none
+	Weight int
+	// Layers lists the do_objectIDs of the text layers using the font
+	Layers []string
+	// TextStyles lists the do_objectIDs of the shared text styles using the font
+	TextStyles []string
+}
+
+// Fonts reports the fonts used by text layers and shared text styles,
+// and the fonts listed in meta.json, sorted by name
+func (f *File) Fonts() []*FontUsage {
+	fonts := map[string]*FontUsage{}
+	use := func(attrs *EncodedAttributes) *FontUsage {
+		if attrs == nil || attrs.MSAttributedStringFontAttribute == nil {
+			return nil
+		}
+		desc := attrs.MSAttributedStringFontAttribute.Attributes
+		if desc == nil || desc.Name == "" {
+			return nil
+		}
+
+		u, ok := fonts[desc.Name]
+		if !ok {
+			u = &FontUsage{Name: desc.Name, Weight: fontWeight(desc.Name)}
+			fonts[desc.Name] = u
+		}
+		if size, err := desc.Size.Float64(); err == nil && !slices.Contains(u.Sizes, size) {
+			u.Sizes = append(u.Sizes, size)
+		}
+		return u
+	}
+
+	if f.Document.LayerTextStyles != nil {
+		for _, s := range f.Document.LayerTextStyles.Objects {
+			if s == nil {
+				continue
+			}
+			if u := use(textAttributes(s.Value)); u != nil {
+				u.TextStyles = appendUnique(u.TextStyles, s.DoObjectID)
+			}
+		}
+	}
+
+	for _, page := range f.Pages {
+		if page == nil {
+			continue
+		}
+		walkLayers(page.Layers, func(layer Layer) error {
+			text, ok := layer.(*Text)
+			if !ok {
+				return nil
+			}
+			if u := use(textAttributes(text.Style)); u != nil {
+				u.Layers = appendUnique(u.Layers, text.DoObjectID)
+			}
+			if text.AttributedString != nil {
+				for _, attr := range text.AttributedString.Attributes {
+					if attr == nil {
+						continue
+					}
+					if u := use(attr.Attributes); u != nil {
+						u.Layers = appendUnique(u.Layers, text.DoObjectID)
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, name := range f.Meta.Fonts {
+		if _, ok := fonts[name]; !ok && name != "" {
+			fonts[name] = &FontUsage{Name: name, Weight: fontWeight(name)}
+		}
+	}
+
+	out := make([]*FontUsage, 0, len(fonts))
+	for _, u := range fonts {
+		sort.Float64s(u.Sizes)
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func textAttributes(s *Style) *EncodedAttributes {
+	if s == nil || s.TextStyle == nil {
+		return nil
+	}
+	return s.TextStyle.EncodedAttributes
+}
+
+func appendUnique(ids []string, id string) []string {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
+
+// fontWeights maps the style part of PostScript names to CSS weights,
+// longer names first so `ExtraBold` is not taken for `Bold`
+var fontWeights = []struct {
+	style  string
+	weight int
+}{
+	{"ultralight", 100}, {"extralight", 200}, {"semibold", 600}, {"demibold", 600},
+	{"extrabold", 800}, {"ultrabold", 800}, {"hairline", 100}, {"thin", 100},
+	{"light", 300}, {"book", 400}, {"regular", 400}, {"medium", 500},
+	{"bold", 700}, {"heavy", 900}, {"black", 900},
+}
+
+func fontWeight(name string) int {
+	_, style, ok := strings.Cut(name, "-")
+	if !ok {
+		return 400
+	}
+	style = strings.ToLower(style)
+	for _, w := range fontWeights {
+		if strings.Contains(style, w.style) {
+			return w.weight
+		}
+	}
+	return 400
+}
+
+// MissingFonts returns the fonts with no font file in dir providing
+// their PostScript name. Files that are not valid fonts are ignored
+func MissingFonts(fonts []*FontUsage, dir string) ([]*FontUsage, error) {
+	installed := map[string]bool{}
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".ttf", ".otf", ".ttc", ".otc":
+		default:
+			return nil
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		names, err := sfntPostScriptNames(f)
+		if err != nil {
+			return nil
+		}
+		for _, n := range names {
+			installed[n] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []*FontUsage{}
+	for _, u := range fonts {
+		if !installed[u.Name] {
+			missing = append(missing, u)
+		}
+	}
+	return missing, nil
+}
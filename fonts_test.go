@@ -0,0 +1,174 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+// nameRecord is a name of the name table of a font
+type nameRecord struct {
+	platform uint16
+	nameID   uint16
+	value    string
+}
+
+// sfntFont is a font holding only a name table with records, for a
+// font file where it starts at offset
+func sfntFont(offset int, records ...nameRecord) []byte {
+	be := binary.BigEndian
+	storage := []byte{}
+	table := be.AppendUint16(nil, 0)
+	table = be.AppendUint16(table, uint16(len(records)))
+	table = be.AppendUint16(table, uint16(6+12*len(records)))
+	for _, r := range records {
+		value := []byte(r.value)
+		if r.platform != 1 {
+			value = nil
+			for _, u := range utf16.Encode([]rune(r.value)) {
+				value = be.AppendUint16(value, u)
+			}
+		}
+		for _, v := range []uint16{r.platform, 0, 0, r.nameID, uint16(len(value)), uint16(len(storage))} {
+			table = be.AppendUint16(table, v)
+		}
+		storage = append(storage, value...)
+	}
+	table = append(table, storage...)
+
+	font := []byte("\x00\x01\x00\x00")
+	font = be.AppendUint16(font, 1)
+	font = append(font, make([]byte, 6)...)
+	font = append(font, "name"...)
+	font = be.AppendUint32(font, 0)
+	font = be.AppendUint32(font, uint32(offset+len(font)+8))
+	font = be.AppendUint32(font, uint32(len(table)))
+	return append(font, table...)
+}
+
+// sfntCollection is a font collection of fonts with the given names
+func sfntCollection(names ...string) []byte {
+	be := binary.BigEndian
+	header := []byte("ttcf\x00\x01\x00\x00")
+	header = be.AppendUint32(header, uint32(len(names)))
+
+	fonts := []byte{}
+	start := len(header) + 4*len(names)
+	for _, name := range names {
+		header = be.AppendUint32(header, uint32(start+len(fonts)))
+		fonts = append(fonts, sfntFont(start+len(fonts), nameRecord{3, 6, name})...)
+	}
+	return append(header, fonts...)
+}
+
+func TestSfntPostScriptNames(t *testing.T) {
+	tests := []struct {
+		font []byte
+		want []string
+	}{
+		{sfntFont(0, nameRecord{3, 1, "Inter"}, nameRecord{3, 6, "Inter-Bold"}), []string{"Inter-Bold"}},
+		{sfntFont(0, nameRecord{1, 6, "Mac-Regular"}), []string{"Mac-Regular"}},
+		{sfntFont(0, nameRecord{1, 6, "Mac-Name"}, nameRecord{0, 6, "Unicode-Name"}), []string{"Unicode-Name"}},
+		{sfntCollection("Avenir-Book", "Avenir-Heavy"), []string{"Avenir-Book", "Avenir-Heavy"}},
+	}
+	for _, tt := range tests {
+		got, err := sfntPostScriptNames(bytes.NewReader(tt.font))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+
+	for _, font := range [][]byte{
+		sfntFont(0, nameRecord{3, 1, "Inter"}),
+		[]byte("not a font at all"),
+	} {
+		if names, err := sfntPostScriptNames(bytes.NewReader(font)); err == nil {
+			t.Errorf("got %q for a font without a PostScript name", names)
+		}
+	}
+
+	// truncated fonts fail without reading out of bounds
+	for _, font := range [][]byte{sfntFont(0, nameRecord{3, 6, "Inter-Bold"}), sfntCollection("A", "B")} {
+		for n := 0; n < len(font); n++ {
+			if names, err := sfntPostScriptNames(bytes.NewReader(font[:n])); err == nil {
+				t.Errorf("got %q from %d of %d bytes", names, n, len(font))
+			}
+		}
+	}
+}
+
+// fontsFile uses Inter-Bold in a text layer and a text style, Avenir-Book
+// in a run of the layer, and lists Missing-Font in meta.json
+func fontsFile() *File {
+	font := func(name, size string) *EncodedAttributes {
+		return &EncodedAttributes{MSAttributedStringFontAttribute: &FontDescriptor{
+			Class:      "fontDescriptor",
+			Attributes: &FontDescriptorAttributes{Name: name, Size: json.Number(size)},
+		}}
+	}
+	return &File{
+		Meta: Meta{Fonts: []string{"Inter-Bold", "Missing-Font"}},
+		Document: Document{LayerTextStyles: &SharedTextStyleContainer{Objects: []*SharedStyle{
+			{DoObjectID: "S1", Value: &Style{TextStyle: &TextStyle{EncodedAttributes: font("Inter-Bold", "24")}}},
+		}}},
+		Pages: []*Page{{DoObjectID: "P1", Layers: Layers{
+			&Text{
+				LayerBase: LayerBase{DoObjectID: "T1", Style: &Style{TextStyle: &TextStyle{EncodedAttributes: font("Inter-Bold", "12")}}},
+				AttributedString: &MSAttributedString{String: "ab", Attributes: []*StringAttribute{
+					{Location: 0, Length: 1, Attributes: font("Inter-Bold", "12")},
+					{Location: 1, Length: 1, Attributes: font("Avenir-Book", "12")},
+				}},
+			},
+		}}},
+	}
+}
+
+func TestFonts(t *testing.T) {
+	got := fontsFile().Fonts()
+	want := []*FontUsage{
+		{Name: "Avenir-Book", Sizes: []float64{12}, Weight: 400, Layers: []string{"T1"}},
+		{Name: "Inter-Bold", Sizes: []float64{12, 24}, Weight: 700, Layers: []string{"T1"}, TextStyles: []string{"S1"}},
+		{Name: "Missing-Font", Weight: 400},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMissingFonts(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"Inter-Bold.ttf":    sfntFont(0, nameRecord{3, 6, "Inter-Bold"}),
+		"nested/Avenir.ttc": sfntCollection("Avenir-Book", "Avenir-Heavy"),
+		"broken.otf":        []byte("\x00\x01\x00\x00\x00\x09"),
+		"Missing-Font.txt":  sfntFont(0, nameRecord{3, 6, "Missing-Font"}),
+	} {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	missing, err := MissingFonts(fontsFile().Fonts(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0].Name != "Missing-Font" {
+		t.Fatalf("got missing fonts %+v, want Missing-Font", missing)
+	}
+
+	if _, err := MissingFonts(nil, filepath.Join(dir, "none")); err == nil {
+		t.Fatal("no error for a missing font directory")
+	}
+}
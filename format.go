@@ -0,0 +1,83 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Format versions of documents this package can read, as stored
+// in the version property of meta.json
+const (
+	// MinVersion is the format version written by Sketch 43,
+	// the first release storing documents as zipped JSON
+	MinVersion = 88
+	// MaxVersion is the newest format version this package understands
+	MaxVersion = 146
+)
+
+var (
+	// ErrLegacyFormat is returned for documents saved before Sketch 43,
+	// which are SQLite databases or binary plists instead of zipped JSON
+	ErrLegacyFormat = errors.New("legacy sketch format saved before Sketch 43")
+
+	// ErrUnsupportedVersion is matched by *UnsupportedVersionError
+	ErrUnsupportedVersion = errors.New("unsupported sketch format version")
+)
+
+// UnsupportedVersionError is returned for documents whose format
+// version is older than MinVersion or newer than MaxVersion
+type UnsupportedVersionError struct {
+	Version    json.Number
+	Build      json.Number
+	AppVersion string
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%v %s (Sketch %s build %s)", ErrUnsupportedVersion, e.Version, e.AppVersion, e.Build)
+}
+
+func (e *UnsupportedVersionError) Is(target error) bool {
+	return target == ErrUnsupportedVersion
+}
+
+var legacyMagic = [][]byte{
+	[]byte("SQLite format 3\x00"),
+	[]byte("bplist"),
+}
+
+// sniff returns ErrLegacyFormat when r holds a pre Sketch 43 document
+func sniff(r io.ReaderAt) error {
+	head := make([]byte, 16)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	for _, magic := range legacyMagic {
+		if bytes.HasPrefix(head[:n], magic) {
+			return ErrLegacyFormat
+		}
+	}
+	return nil
+}
+
+// checkVersion returns an *UnsupportedVersionError when meta
+// describes a document older than MinVersion or newer than MaxVersion.
+// Documents without a version, or written with a zero one from a File
+// whose Meta was left empty, are accepted
+func checkVersion(meta *Meta) error {
+	v, err := meta.Version.Int64()
+	if err != nil || v == 0 || (v >= MinVersion && v <= MaxVersion) {
+		return nil
+	}
+
+	return &UnsupportedVersionError{
+		Version:    meta.Version,
+		Build:      meta.Build,
+		AppVersion: meta.AppVersion,
+	}
+}
@@ -0,0 +1,32 @@
+package sketch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		version json.Number
+		ok      bool
+	}{
+		{"", true},
+		{"0", true},
+		{"1", false},
+		{"87", false},
+		{"88", true},
+		{"146", true},
+		{"147", false},
+	}
+	for _, tt := range tests {
+		err := checkVersion(&Meta{Version: tt.version})
+		if tt.ok && err != nil {
+			t.Errorf("version %q: %v", tt.version, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("version %q: got %v, want ErrUnsupportedVersion", tt.version, err)
+		}
+	}
+}
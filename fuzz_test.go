@@ -0,0 +1,119 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func FuzzParseBytes(f *testing.F) {
+	file, err := ParseFS(minimalFS())
+	if err != nil {
+		f.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := file.WriteTo(buf); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf.Bytes())
+	f.Add([]byte("{}"))
+	f.Add([]byte("{1}"))
+	f.Add([]byte("PK\x03\x04"))
+	f.Add([]byte("bplist00"))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		ParseBytes(b)
+	})
+}
+
+func FuzzPositionCoordinates(f *testing.F) {
+	for _, s := range []string{"{}", "{1}", "{0.5, 0.67135115527602085}", "{1, 2, 3}", "{{1, 2}}", "{a, b}", "{-1e3, 2E-2}"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		in, _ := json.Marshal(s)
+		p := &PositionCoordinates{}
+		if err := p.UnmarshalJSON(in); err != nil {
+			return
+		}
+		out, err := json.Marshal(p)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		got := &PositionCoordinates{}
+		if err := json.Unmarshal(out, got); err != nil {
+			t.Fatalf("%q encoded as %s: %v", s, out, err)
+		}
+		if *got != *p {
+			t.Fatalf("%q: got %v after encoding as %s, want %v", s, got, out, p)
+		}
+	})
+}
+
+func FuzzNestedPositionCoordinates(f *testing.F) {
+	for _, s := range []string{"{}", "{1}", "{{1}}", "{{0, 3}, {36, 14}}", "{{1, 2}, 3}", "{{{1, 2}}}", "{{a, b}}"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		in, _ := json.Marshal(s)
+		n := &NestedPositionCoordinates{}
+		if err := n.UnmarshalJSON(in); err != nil {
+			return
+		}
+		out, err := json.Marshal(n)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		got := &NestedPositionCoordinates{}
+		if err := json.Unmarshal(out, got); err != nil {
+			t.Fatalf("%q encoded as %s: %v", s, out, err)
+		}
+		if len(got.Data) != len(n.Data) {
+			t.Fatalf("%q: got %d points after encoding as %s, want %d", s, len(got.Data), out, len(n.Data))
+		}
+		for i := range got.Data {
+			if *got.Data[i] != *n.Data[i] {
+				t.Fatalf("%q: got point %d %v after encoding as %s, want %v", s, i, got.Data[i], out, n.Data[i])
+			}
+		}
+	})
+}
+
+func FuzzArchive(f *testing.F) {
+	for _, raw := range readCoordinates(f).Archives {
+		plist := []byte{}
+		if err := json.Unmarshal(raw, &plist); err != nil {
+			f.Fatal(err)
+		}
+		f.Add(plist)
+	}
+	f.Add([]byte("{}"))
+	f.Add([]byte("{1}"))
+	f.Add([]byte("bplist00"))
+
+	f.Fuzz(func(t *testing.T, plist []byte) {
+		a := &ArchivedAttributedString{}
+		in, _ := json.Marshal(map[string]string{"_archive": base64.StdEncoding.EncodeToString(plist)})
+		if err := json.Unmarshal(in, a); err != nil {
+			t.Fatal(err)
+		}
+		s, err := a.AttributedString()
+		if err != nil || s.checkRuns() != nil {
+			return
+		}
+
+		if err := a.SetAttributedString(s); err != nil {
+			t.Fatal(err)
+		}
+		got, err := a.AttributedString()
+		if err != nil {
+			t.Fatalf("archived string does not decode: %v", err)
+		}
+		if got.Text != s.Text || len(got.Runs) != len(s.Runs) {
+			t.Fatalf("got %q in %d runs, want %q in %d runs", got.Text, len(got.Runs), s.Text, len(s.Runs))
+		}
+	})
+}
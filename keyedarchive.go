@@ -0,0 +1,229 @@
+package sketch
+
+import (
+	"github.com/pkg/errors"
+	"howett.net/plist"
+)
+
+// keyedArchive resolves the object graph of an NSKeyedArchiver plist,
+// where objects reference each other by index into `$objects`
+type keyedArchive struct {
+	objects []interface{}
+	top     map[string]interface{}
+}
+
+func newKeyedArchive(data map[string]interface{}) (*keyedArchive, error) {
+	if archiver, _ := data["$archiver"].(string); archiver != "NSKeyedArchiver" {
+		return nil, errors.Errorf("not an NSKeyedArchiver archive: %q", archiver)
+	}
+
+	objects, ok := data["$objects"].([]interface{})
+	if !ok {
+		return nil, errors.New("keyed archive has no $objects")
+	}
+	top, ok := data["$top"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("keyed archive has no $top")
+	}
+
+	return &keyedArchive{objects: objects, top: top}, nil
+}
+
+// archiveRoot decodes a and returns its root object
+func archiveRoot(a *Archive) (*keyedArchive, map[string]interface{}, error) {
+	data, err := a.Data()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k, err := newKeyedArchive(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root, err := k.root()
+	if err != nil {
+		return nil, nil, err
+	}
+	return k, root, nil
+}
+
+// root returns the object archived as the root of the graph
+func (k *keyedArchive) root() (map[string]interface{}, error) {
+	root, ok := k.resolve(k.top["root"]).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("keyed archive root is not an object")
+	}
+	return root, nil
+}
+
+// resolve follows v when it is a reference into `$objects`,
+// the `$null` placeholder resolves to nil
+func (k *keyedArchive) resolve(v interface{}) interface{} {
+	uid, ok := v.(plist.UID)
+	if !ok {
+		return v
+	}
+	if int(uid) < 0 || int(uid) >= len(k.objects) {
+		return nil
+	}
+
+	obj := k.objects[uid]
+	if s, ok := obj.(string); ok && s == "$null" {
+		return nil
+	}
+	return obj
+}
+
+// string resolves v as an NSString, either stored inline
+// or as an NSMutableString object
+func (k *keyedArchive) string(v interface{}) (string, bool) {
+	switch s := k.resolve(v).(type) {
+	case string:
+		return s, true
+	case map[string]interface{}:
+		str, ok := k.resolve(s["NS.string"]).(string)
+		return str, ok
+	}
+	return "", false
+}
+
+// dict resolves v as an NSDictionary, with the keys and values resolved
+func (k *keyedArchive) dict(v interface{}) (map[string]interface{}, bool) {
+	obj, ok := k.resolve(v).(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	keys, _ := obj["NS.keys"].([]interface{})
+	values, _ := obj["NS.objects"].([]interface{})
+	if len(keys) != len(values) {
+		return nil, false
+	}
+
+	dict := make(map[string]interface{}, len(keys))
+	for i, key := range keys {
+		name, ok := k.string(key)
+		if !ok {
+			return nil, false
+		}
+		dict[name] = values[i]
+	}
+	return dict, true
+}
+
+// array resolves v as an NSArray, the elements are left unresolved
+func (k *keyedArchive) array(v interface{}) ([]interface{}, bool) {
+	obj, ok := k.resolve(v).(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	values, ok := obj["NS.objects"].([]interface{})
+	return values, ok
+}
+
+// data resolves v as NSData, either stored inline or as an object
+func (k *keyedArchive) data(v interface{}) ([]byte, bool) {
+	switch d := k.resolve(v).(type) {
+	case []byte:
+		return d, true
+	case map[string]interface{}:
+		b, ok := k.resolve(d["NS.bytes"]).([]byte)
+		return b, ok
+	}
+	return nil, false
+}
+
+// number resolves v as an NSNumber
+func (k *keyedArchive) number(v interface{}) (float64, bool) {
+	switch n := k.resolve(v).(type) {
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// keyedArchiver builds the object graph of an NSKeyedArchiver plist
+type keyedArchiver struct {
+	objects []interface{}
+	classes map[string]plist.UID
+}
+
+func newKeyedArchiver() *keyedArchiver {
+	return &keyedArchiver{
+		// the root object is archived at index 1, after `$null`
+		objects: []interface{}{"$null", nil},
+		classes: map[string]plist.UID{},
+	}
+}
+
+// add appends v to `$objects` and returns its reference
+func (k *keyedArchiver) add(v interface{}) plist.UID {
+	k.objects = append(k.objects, v)
+	return plist.UID(len(k.objects) - 1)
+}
+
+// class returns the reference to the class description of classes,
+// the archived class followed by its super classes
+func (k *keyedArchiver) class(classes ...string) plist.UID {
+	if uid, ok := k.classes[classes[0]]; ok {
+		return uid
+	}
+
+	names := make([]interface{}, 0, len(classes))
+	for _, name := range classes {
+		names = append(names, name)
+	}
+	uid := k.add(map[string]interface{}{
+		"$classname": classes[0],
+		"$classes":   names,
+	})
+	k.classes[classes[0]] = uid
+	return uid
+}
+
+// object adds an object of the class described by classes
+func (k *keyedArchiver) object(fields map[string]interface{}, classes ...string) plist.UID {
+	fields["$class"] = k.class(classes...)
+	return k.add(fields)
+}
+
+// dict adds an NSDictionary of the given keys and references
+func (k *keyedArchiver) dict(keys []string, values []plist.UID) plist.UID {
+	nsKeys := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		nsKeys = append(nsKeys, k.add(key))
+	}
+	nsValues := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		nsValues = append(nsValues, v)
+	}
+	return k.object(map[string]interface{}{
+		"NS.keys":    nsKeys,
+		"NS.objects": nsValues,
+	}, "NSDictionary", "NSObject")
+}
+
+// archive returns the plist of the graph, with the object
+// at index 1 as root
+func (k *keyedArchiver) archive(root map[string]interface{}, classes ...string) map[string]interface{} {
+	root["$class"] = k.class(classes...)
+	k.objects[1] = root
+
+	return map[string]interface{}{
+		"$version":  int64(100000),
+		"$archiver": "NSKeyedArchiver",
+		"$top":      map[string]interface{}{"root": plist.UID(1)},
+		"$objects":  k.objects,
+	}
+}
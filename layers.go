@@ -0,0 +1,364 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// Layer is a node of a page layer tree. The concrete type is chosen
+// from the `_class` of the node, use a type switch to access the
+// properties specific to a kind of layer
+type Layer interface {
+	// Base returns the properties shared by every kind of layer
+	Base() *LayerBase
+}
+
+// LayerContainer is implemented by layers that hold child layers
+type LayerContainer interface {
+	Layer
+	Children() Layers
+}
+
+// LayerBase holds the properties shared by every kind of layer
+type LayerBase struct {
+	Class                 string          `json:"_class"`
+	DoObjectID            string          `json:"do_objectID"`
+	ExportOptions         *ExportOptions  `json:"exportOptions"`
+	Frame                 *Rect           `json:"frame"`
+	IsFlippedHorizontal   bool            `json:"isFlippedHorizontal"`
+	IsFlippedVertical     bool            `json:"isFlippedVertical"`
+	IsLocked              bool            `json:"isLocked"`
+	IsVisible             bool            `json:"isVisible"`
+	LayerListExpandedType json.Number     `json:"layerListExpandedType"`
+	Flow                  *FlowConnection `json:"flow,omitempty"`
+	Name                  string          `json:"name"`
+	NameIsFixed           bool            `json:"nameIsFixed"`
+	OriginalObjectID      string          `json:"originalObjectID,omitempty"`
+	ResizingConstraint    json.Number     `json:"resizingConstraint"`
+	ResizingType          int64           `json:"resizingType"`
+	Rotation              json.Number     `json:"rotation"`
+	ShouldBreakMaskChain  bool            `json:"shouldBreakMaskChain"`
+	Style                 *Style          `json:"style"`
+	Extra                 Extra           `json:"-"`
+}
+
+func (l *LayerBase) Base() *LayerBase {
+	return l
+}
+
+// GroupBase holds the properties shared by layers that contain other layers
+type GroupBase struct {
+	LayerBase
+	GroupLayout     *GroupLayout `json:"groupLayout,omitempty"`
+	HasClickThrough bool         `json:"hasClickThrough"`
+	Layers          Layers       `json:"layers"`
+}
+
+func (g *GroupBase) Children() Layers {
+	return g.Layers
+}
+
+// ShapeBase holds the properties shared by the shape layers.
+// Documents before Sketch 52 keep the points in Path, they are
+// migrated to Points and IsClosed when parsed
+type ShapeBase struct {
+	LayerBase
+	BooleanOperation int64         `json:"booleanOperation"`
+	Edited           bool          `json:"edited"`
+	IsClosed         bool          `json:"isClosed"`
+	Path             *Path         `json:"path,omitempty"`
+	Points           []*CurvePoint `json:"points"`
+}
+
+// FlowConnection is a prototyping link to another artboard
+type FlowConnection struct {
+	Class                  string      `json:"_class"`
+	AnimationType          json.Number `json:"animationType"`
+	DestinationArtboardID  string      `json:"destinationArtboardID"`
+	MaintainScrollPosition bool        `json:"maintainScrollPosition,omitempty"`
+	Extra                  Extra       `json:"-"`
+}
+
+// GroupLayout is the smart layout of a group
+type GroupLayout struct {
+	Class        string      `json:"_class"`
+	Axis         json.Number `json:"axis,omitempty"`
+	LayoutAnchor json.Number `json:"layoutAnchor,omitempty"`
+	MaxSize      json.Number `json:"maxSize,omitempty"`
+	MinSize      json.Number `json:"minSize,omitempty"`
+	Extra        Extra       `json:"-"`
+}
+
+// OverrideValue replaces a property of a layer inside a symbol instance.
+// OverrideName is the path of do_objectIDs to the layer joined by `/`,
+// followed by the overridden property, like `<id>_stringValue`
+type OverrideValue struct {
+	Class        string      `json:"_class"`
+	OverrideName string      `json:"overrideName"`
+	Value        interface{} `json:"value"`
+	Extra        Extra       `json:"-"`
+}
+
+type Group struct {
+	GroupBase
+}
+
+type ShapeGroup struct {
+	GroupBase
+	ClippingMaskMode json.Number `json:"clippingMaskMode"`
+	HasClippingMask  bool        `json:"hasClippingMask"`
+	WindingRule      json.Number `json:"windingRule"`
+}
+
+type Artboard struct {
+	GroupBase
+	BackgroundColor                *Color      `json:"backgroundColor"`
+	HasBackgroundColor             bool        `json:"hasBackgroundColor"`
+	HorizontalRulerData            *RulerData  `json:"horizontalRulerData"`
+	IncludeBackgroundColorInExport bool        `json:"includeBackgroundColorInExport"`
+	IncludeInCloudUpload           bool        `json:"includeInCloudUpload"`
+	IsFlowHome                     bool        `json:"isFlowHome,omitempty"`
+	Layout                         *LayoutGrid `json:"layout,omitempty"`
+	ResizesContent                 bool        `json:"resizesContent"`
+	VerticalRulerData              *RulerData  `json:"verticalRulerData"`
+}
+
+type SymbolMaster struct {
+	GroupBase
+	BackgroundColor                  *Color      `json:"backgroundColor"`
+	HasBackgroundColor               bool        `json:"hasBackgroundColor"`
+	HorizontalRulerData              *RulerData  `json:"horizontalRulerData"`
+	IncludeBackgroundColorInExport   bool        `json:"includeBackgroundColorInExport"`
+	IncludeBackgroundColorInInstance bool        `json:"includeBackgroundColorInInstance"`
+	IncludeInCloudUpload             bool        `json:"includeInCloudUpload"`
+	IsFlowHome                       bool        `json:"isFlowHome,omitempty"`
+	Layout                           *LayoutGrid `json:"layout,omitempty"`
+	ResizesContent                   bool        `json:"resizesContent"`
+	SymbolID                         string      `json:"symbolID"`
+	VerticalRulerData                *RulerData  `json:"verticalRulerData"`
+}
+
+type SymbolInstance struct {
+	LayerBase
+	HorizontalSpacing              json.Number      `json:"horizontalSpacing"`
+	MasterInfluenceEdgeMaxXPadding json.Number      `json:"masterInfluenceEdgeMaxXPadding"`
+	MasterInfluenceEdgeMaxYPadding json.Number      `json:"masterInfluenceEdgeMaxYPadding"`
+	MasterInfluenceEdgeMinXPadding json.Number      `json:"masterInfluenceEdgeMinXPadding"`
+	MasterInfluenceEdgeMinYPadding json.Number      `json:"masterInfluenceEdgeMinYPadding"`
+	OverrideValues                 []*OverrideValue `json:"overrideValues"`
+	Overrides                      *Overrides       `json:"overrides,omitempty"`
+	SymbolID                       string           `json:"symbolID"`
+	VerticalSpacing                json.Number      `json:"verticalSpacing"`
+}
+
+type Rectangle struct {
+	ShapeBase
+	FixedRadius                   json.Number `json:"fixedRadius"`
+	HasConvertedToNewRoundCorners bool        `json:"hasConvertedToNewRoundCorners"`
+}
+
+type Oval struct {
+	ShapeBase
+}
+
+type ShapePath struct {
+	ShapeBase
+}
+
+type Star struct {
+	ShapeBase
+	NumberOfPoints int64       `json:"numberOfPoints"`
+	Radius         json.Number `json:"radius"`
+}
+
+type Polygon struct {
+	ShapeBase
+	NumberOfPoints int64 `json:"numberOfPoints"`
+}
+
+type Triangle struct {
+	ShapeBase
+	IsEquilateral bool `json:"isEquilateral"`
+}
+
+type Text struct {
+	LayerBase
+	AttributedString                  *MSAttributedString        `json:"attributedString"`
+	AutomaticallyDrawOnUnderlyingPath bool                       `json:"automaticallyDrawOnUnderlyingPath"`
+	DontSynchroniseWithSymbol         bool                       `json:"dontSynchroniseWithSymbol"`
+	GlyphBounds                       *NestedPositionCoordinates `json:"glyphBounds"`
+	HeightIsClipped                   bool                       `json:"heightIsClipped"`
+	LineSpacingBehaviour              json.Number                `json:"lineSpacingBehaviour"`
+	TextBehaviour                     TextBehaviour              `json:"textBehaviour,omitempty"`
+}
+
+type Bitmap struct {
+	LayerBase
+	ClippingMask        *NestedPositionCoordinates `json:"clippingMask"`
+	FillReplacesImage   bool                       `json:"fillReplacesImage"`
+	Image               *MSJSONFileReference       `json:"image"`
+	NineSliceCenterRect *NestedPositionCoordinates `json:"nineSliceCenterRect"`
+	NineSliceScale      *PositionCoordinates       `json:"nineSliceScale"`
+}
+
+type Slice struct {
+	LayerBase
+	BackgroundColor    *Color `json:"backgroundColor"`
+	HasBackgroundColor bool   `json:"hasBackgroundColor"`
+}
+
+type Hotspot struct {
+	LayerBase
+}
+
+// UnknownLayer is a layer with a `_class` this package does not model,
+// the properties beyond LayerBase are kept in Extra
+type UnknownLayer struct {
+	LayerBase
+}
+
+// layerClasses maps the `_class` discriminator to a constructor
+// of the matching concrete layer
+var layerClasses = map[string]func() Layer{
+	"artboard":                func() Layer { return &Artboard{} },
+	"bitmap":                  func() Layer { return &Bitmap{} },
+	"group":                   func() Layer { return &Group{} },
+	"MSImmutableHotspotLayer": func() Layer { return &Hotspot{} },
+	"oval":                    func() Layer { return &Oval{} },
+	"polygon":                 func() Layer { return &Polygon{} },
+	"rectangle":               func() Layer { return &Rectangle{} },
+	"shapeGroup":              func() Layer { return &ShapeGroup{} },
+	"shapePath":               func() Layer { return &ShapePath{} },
+	"slice":                   func() Layer { return &Slice{} },
+	"star":                    func() Layer { return &Star{} },
+	"symbolInstance":          func() Layer { return &SymbolInstance{} },
+	"symbolMaster":            func() Layer { return &SymbolMaster{} },
+	"text":                    func() Layer { return &Text{} },
+	"triangle":                func() Layer { return &Triangle{} },
+}
+
+// layerClassNames maps the concrete layer types to their `_class`
+var layerClassNames = func() map[reflect.Type]string {
+	names := map[reflect.Type]string{}
+	for class, newLayer := range layerClasses {
+		names[reflect.TypeOf(newLayer())] = class
+	}
+	return names
+}()
+
+// Layers is a list of layers decoded by their `_class`
+type Layers []Layer
+
+func (l *Layers) UnmarshalJSON(b []byte) error {
+	out := Layers{}
+	var err error
+	i := 0
+	if !scanArray(b, func(raw []byte) bool {
+		defer func() { i++ }()
+		if string(raw) == "null" {
+			return true
+		}
+		layer, lerr := decodeLayer(raw)
+		if lerr != nil {
+			err = &pathError{key: strconv.Itoa(i), objectID: layerID(raw), err: lerr, layer: true}
+			return false
+		}
+		out = append(out, layer)
+		return true
+	}) {
+		return json.Unmarshal(b, &[]json.RawMessage{})
+	}
+	if err != nil {
+		return err
+	}
+
+	*l = out
+	return nil
+}
+
+// MarshalJSON encodes the layers, giving layers built without
+// a `_class` the one of their type so they decode back to it
+func (l Layers) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('[')
+	for i, layer := range l {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, err := json.Marshal(withClass(layer))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// withClass returns layer, or a copy of it with the `_class`
+// of its type when it has none
+func withClass(layer Layer) Layer {
+	if layer == nil || layer.Base().Class != "" {
+		return layer
+	}
+	v := reflect.ValueOf(layer)
+	c := reflect.New(v.Type().Elem())
+	c.Elem().Set(v.Elem())
+	copied := c.Interface().(Layer)
+	copied.Base().Class = layerClassNames[v.Type()]
+	return copied
+}
+
+// layerID returns the do_objectID of a layer that failed to decode
+func layerID(raw json.RawMessage) string {
+	id := ""
+	scanObject(raw, func(key string, value []byte) bool {
+		if key == "do_objectID" {
+			id, _ = jsonString(value)
+			return false
+		}
+		return true
+	})
+	return id
+}
+
+// decodeLayer decodes the layer raw into the type of its `_class`
+func decodeLayer(raw []byte) (Layer, error) {
+	class := ""
+	scanObject(raw, func(key string, value []byte) bool {
+		if key == "_class" {
+			class, _ = jsonString(value)
+			return false
+		}
+		return true
+	})
+
+	layer := Layer(&UnknownLayer{})
+	if newLayer, ok := layerClasses[class]; ok {
+		layer = newLayer()
+	}
+	if u, ok := layer.(json.Unmarshaler); ok {
+		return layer, u.UnmarshalJSON(raw)
+	}
+	return layer, json.Unmarshal(raw, layer)
+}
+
+// walkLayers calls fn for every layer of the tree in depth first order
+func walkLayers(layers Layers, fn func(Layer) error) error {
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		if err := fn(layer); err != nil {
+			return err
+		}
+		if c, ok := layer.(LayerContainer); ok {
+			if err := walkLayers(c.Children(), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package sketch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeLayerClasses(t *testing.T) {
+	for class, newLayer := range layerClasses {
+		raw := `{"do_objectID":"L1","frame":{},"_class":"` + class + `"}`
+		layer, err := decodeLayer([]byte(raw))
+		if err != nil {
+			t.Fatalf("%s: %v", class, err)
+		}
+		if got, want := reflect.TypeOf(layer), reflect.TypeOf(newLayer()); got != want {
+			t.Errorf("%s: decoded a %v, want a %v", class, got, want)
+		}
+		if b := layer.Base(); b.Class != class || b.DoObjectID != "L1" {
+			t.Errorf("%s: got base %+v", class, b)
+		}
+	}
+
+	for _, raw := range []string{
+		`{"_class":"MSImmutableFutureLayer","do_objectID":"U1","frame":{},"glow":{"radius":4}}`,
+		`{"do_objectID":"U1","frame":{},"glow":{"radius":4}}`,
+	} {
+		layer, err := decodeLayer([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, ok := layer.(*UnknownLayer)
+		if !ok || u.DoObjectID != "U1" || string(u.Extra.Fields["glow"]) != `{"radius":4}` {
+			t.Fatalf("%s: got %#v, want an UnknownLayer keeping its properties", raw, layer)
+		}
+		b, err := json.Marshal(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want interface{}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(raw), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: encoded back as %s", raw, b)
+		}
+	}
+}
+
+func TestLayersNested(t *testing.T) {
+	raw := `[null,{"_class":"artboard","do_objectID":"A1","frame":{},"layers":[` +
+		`{"_class":"group","do_objectID":"G1","frame":{},"layers":[{"_class":"text","do_objectID":"T1","frame":{}}]},` +
+		`{"_class":"symbolInstance","do_objectID":"I1","frame":{},"symbolID":"S1"}]}]`
+
+	var layers Layers
+	if err := json.Unmarshal([]byte(raw), &layers); err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	if err := walkLayers(layers, func(l Layer) error {
+		got = append(got, reflect.TypeOf(l).Elem().Name()+" "+l.Base().DoObjectID)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Artboard A1", "Group G1", "Text T1", "SymbolInstance I1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got layers %q, want %q", got, want)
+	}
+	if id := layers[0].(*Artboard).Layers[1].(*SymbolInstance).SymbolID; id != "S1" {
+		t.Fatalf("got symbol ID %q", id)
+	}
+
+	if err := json.Unmarshal([]byte(`[{"_class":"group","layers":{}}]`), &Layers{}); err == nil {
+		t.Error("decoded a group whose layers are not an array")
+	}
+}
@@ -0,0 +1,193 @@
+package sketch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Limits bound the resources parsing an untrusted file may use,
+// a zero field is not limited
+type Limits struct {
+	// MaxEntries is the number of entries of the archive
+	MaxEntries int
+	// MaxEntrySize is the decompressed size of a single entry
+	MaxEntrySize int64
+	// MaxTotalSize is the decompressed size of all entries read
+	MaxTotalSize int64
+	// MaxLayerDepth is the nesting depth of layers in an entry
+	MaxLayerDepth int
+}
+
+var (
+	ErrTooManyEntries    = errors.New("too many entries")
+	ErrEntryTooLarge     = errors.New("entry too large")
+	ErrTotalSizeExceeded = errors.New("total size exceeded")
+	ErrLayerDepth        = errors.New("layers nested too deep")
+
+	// ErrInvalidEntryName is returned for absolute entry names,
+	// and names escaping the archive with `..`
+	ErrInvalidEntryName = errors.New("invalid entry name")
+)
+
+func (l Limits) zero() bool {
+	return l == Limits{}
+}
+
+// checkEntries checks the entry names of a zip archive, and
+// the entry count and sizes it declares against limits
+func checkEntries(fsys fs.FS, limits Limits) error {
+	zr, ok := fsys.(*zip.Reader)
+	if !ok {
+		return nil
+	}
+
+	if limits.MaxEntries > 0 && len(zr.File) > limits.MaxEntries {
+		return errors.Wrapf(ErrTooManyEntries, "%d entries", len(zr.File))
+	}
+
+	var total uint64
+	for _, f := range zr.File {
+		if !validEntryName(f.Name) {
+			return &ParseError{Entry: f.Name, Err: ErrInvalidEntryName}
+		}
+		if limits.MaxEntrySize > 0 && f.UncompressedSize64 > uint64(limits.MaxEntrySize) {
+			return &ParseError{Entry: f.Name, Err: ErrEntryTooLarge}
+		}
+		total += f.UncompressedSize64
+		if limits.MaxTotalSize > 0 && total > uint64(limits.MaxTotalSize) {
+			return ErrTotalSizeExceeded
+		}
+	}
+	return nil
+}
+
+func validEntryName(name string) bool {
+	name = strings.TrimSuffix(strings.ReplaceAll(name, `\`, "/"), "/")
+	if name == "" || path.IsAbs(name) || (len(name) > 1 && name[1] == ':') {
+		return false
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// limitFS enforces the size limits on the bytes actually read,
+// as the sizes declared by a zip archive can not be trusted
+type limitFS struct {
+	fs.FS
+	limits Limits
+	total  *atomic.Int64
+}
+
+func newLimitFS(fsys fs.FS, limits Limits) *limitFS {
+	return &limitFS{FS: fsys, limits: limits, total: &atomic.Int64{}}
+}
+
+func (l *limitFS) Open(name string) (fs.File, error) {
+	f, err := l.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		return f, nil
+	}
+	return &limitFile{File: f, fsys: l, name: name}, nil
+}
+
+type limitFile struct {
+	fs.File
+	fsys *limitFS
+	name string
+	n    int64
+}
+
+func (f *limitFile) Read(b []byte) (int, error) {
+	n, err := f.File.Read(b)
+	f.n += int64(n)
+
+	limits := f.fsys.limits
+	if limits.MaxEntrySize > 0 && f.n > limits.MaxEntrySize {
+		return n, &ParseError{Entry: f.name, Err: ErrEntryTooLarge}
+	}
+	if total := f.fsys.total.Add(int64(n)); limits.MaxTotalSize > 0 && total > limits.MaxTotalSize {
+		return n, ErrTotalSizeExceeded
+	}
+	return n, err
+}
+
+// checkLayerDepth scans the JSON of entry for layers nested
+// deeper than max, before anything is decoded
+func checkLayerDepth(entry string, data []byte, max int) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	// layers[i] is set when the i-th open array is a `layers` array
+	layers := []bool{}
+	depth := 0
+	key := ""
+	inObject := []bool{}
+	expectKey := false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// leave reporting the error to decoding
+			return nil
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '[':
+				isLayers := key == "layers"
+				layers = append(layers, isLayers)
+				inObject = append(inObject, false)
+				if isLayers {
+					depth++
+					if depth > max {
+						return &ParseError{Entry: entry, Err: errors.Wrapf(ErrLayerDepth, "more than %d levels", max)}
+					}
+				}
+			case '{':
+				layers = append(layers, false)
+				inObject = append(inObject, true)
+			case ']', '}':
+				if layers[len(layers)-1] {
+					depth--
+				}
+				layers = layers[:len(layers)-1]
+				inObject = inObject[:len(inObject)-1]
+			}
+			key = ""
+			expectKey = len(inObject) > 0 && inObject[len(inObject)-1]
+			continue
+		case string:
+			if expectKey {
+				key = t
+				expectKey = false
+				continue
+			}
+		}
+		key = ""
+		expectKey = len(inObject) > 0 && inObject[len(inObject)-1]
+	}
+}
@@ -0,0 +1,204 @@
+package sketch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+)
+
+// zipOf archives the files of fsys, in the order of their names
+func zipOf(t testing.TB, fsys fstest.MapFS) []byte {
+	t.Helper()
+	names := []string{}
+	for name := range fsys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, name := range names {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(fsys[name].Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func parseZip(b []byte, limits Limits) (*File, error) {
+	return ParseReaderWithOptions(bytes.NewReader(b), int64(len(b)), ParseOptions{Limits: limits})
+}
+
+func TestValidEntryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"pages/P1.json", true},
+		{"images/a..b.png", true},
+		{"previews/", true},
+		{"../evil.json", false},
+		{"pages/../../evil.json", false},
+		{`..\evil.json`, false},
+		{`pages\..\..\evil.json`, false},
+		{"/etc/passwd", false},
+		{`\evil.json`, false},
+		{"C:/evil.json", false},
+		{`C:\evil.json`, false},
+		{"c:evil.json", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := validEntryName(tt.name); got != tt.want {
+			t.Errorf("validEntryName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejectsUnsafeEntryNames(t *testing.T) {
+	for _, name := range []string{"../evil.json", "/evil.json", "C:/evil.json", `images\..\..\evil.png`} {
+		fsys := minimalFS()
+		fsys[name] = &fstest.MapFile{Data: []byte("{}")}
+
+		_, err := parseZip(zipOf(t, fsys), Limits{})
+		var perr *ParseError
+		if !errors.As(err, &perr) || !errors.Is(err, ErrInvalidEntryName) || perr.Entry != name {
+			t.Errorf("%q: got %v, want ErrInvalidEntryName for the entry", name, err)
+		}
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	b := zipOf(t, minimalFS())
+	if _, err := parseZip(b, Limits{MaxEntries: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseZip(b, Limits{MaxEntries: 2}); !errors.Is(err, ErrTooManyEntries) {
+		t.Fatalf("got %v, want ErrTooManyEntries", err)
+	}
+}
+
+func TestDeclaredSizes(t *testing.T) {
+	fsys := minimalFS()
+	b := zipOf(t, fsys)
+	doc := int64(len(fsys["document.json"].Data))
+
+	_, err := parseZip(b, Limits{MaxEntrySize: doc - 1})
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrEntryTooLarge) || perr.Entry != "document.json" {
+		t.Fatalf("got %v, want ErrEntryTooLarge for document.json", err)
+	}
+	if _, err := parseZip(b, Limits{MaxEntrySize: doc}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseZip(b, Limits{MaxTotalSize: doc}); !errors.Is(err, ErrTotalSizeExceeded) {
+		t.Fatalf("got %v, want ErrTotalSizeExceeded", err)
+	}
+}
+
+// A file system that declares no sizes, like an exploded directory,
+// is only bounded by the bytes read
+func TestReadSizes(t *testing.T) {
+	fsys := minimalFS()
+	fsys["pages/P1.json"].Data = []byte(`{"_class":"page","do_objectID":"P1","name":"` + strings.Repeat("x", 1000) + `","layers":[]}`)
+	page := int64(len(fsys["pages/P1.json"].Data))
+
+	_, err := ParseFSContext(context.Background(), fsys, ParseOptions{Limits: Limits{MaxEntrySize: page - 1}})
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrEntryTooLarge) || perr.Entry != "pages/P1.json" {
+		t.Fatalf("got %v, want ErrEntryTooLarge for pages/P1.json", err)
+	}
+
+	total := int64(0)
+	for _, f := range fsys {
+		total += int64(len(f.Data))
+	}
+	if _, err := ParseFSContext(context.Background(), fsys, ParseOptions{Limits: Limits{MaxTotalSize: total}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFSContext(context.Background(), fsys, ParseOptions{Limits: Limits{MaxTotalSize: total - 1}}); !errors.Is(err, ErrTotalSizeExceeded) {
+		t.Fatalf("got %v, want ErrTotalSizeExceeded", err)
+	}
+}
+
+// An entry declaring fewer bytes than it holds passes checkEntries,
+// archive/zip then fails reading past the declared size so the
+// limits are never exceeded
+func TestUnderstatedEntrySize(t *testing.T) {
+	fsys := minimalFS()
+	page := []byte(`{"_class":"page","do_objectID":"P1","name":"` + strings.Repeat("x", 1000) + `","layers":[]}`)
+	delete(fsys, "pages/P1.json")
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, name := range []string{"document.json", "meta.json"} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(fsys[name].Data)
+	}
+	f, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "pages/P1.json",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(page),
+		CompressedSize64:   uint64(len(page)),
+		UncompressedSize64: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(page)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, limits := range []Limits{{MaxEntrySize: 500}, {MaxTotalSize: 500}} {
+		got, err := parseZip(buf.Bytes(), limits)
+		if got != nil || !errors.Is(err, zip.ErrFormat) {
+			t.Fatalf("%+v: got %v, want zip.ErrFormat", limits, err)
+		}
+	}
+}
+
+func TestMaxLayerDepth(t *testing.T) {
+	nested := `{"_class":"group","do_objectID":"G1","frame":{},"layers":[{"_class":"group","do_objectID":"G2","frame":{},"layers":[]}]}`
+
+	fsys := minimalFS()
+	fsys["pages/P1.json"].Data = []byte(`{"_class":"page","do_objectID":"P1","name":"Page 1","layers":[` + nested + `]}`)
+	b := zipOf(t, fsys)
+	if _, err := parseZip(b, Limits{MaxLayerDepth: 3}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := parseZip(b, Limits{MaxLayerDepth: 2})
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrLayerDepth) || perr.Entry != "pages/P1.json" {
+		t.Fatalf("got %v, want ErrLayerDepth for pages/P1.json", err)
+	}
+
+	fsys = minimalFS()
+	fsys["document.json"].Data = []byte(`{"_class":"document","do_objectID":"D1","pages":[{"_ref":"pages/P1"}],` +
+		`"layerSymbols":{"_class":"symbolContainer","objects":[{"_class":"symbolMaster","do_objectID":"S1","symbolID":"S1","frame":{},"layers":[` + nested + `]}]}}`)
+	b = zipOf(t, fsys)
+	if _, err := parseZip(b, Limits{MaxLayerDepth: 3}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = parseZip(b, Limits{MaxLayerDepth: 2})
+	if !errors.As(err, &perr) || !errors.Is(err, ErrLayerDepth) || perr.Entry != "document.json" {
+		t.Fatalf("got %v, want ErrLayerDepth for document.json", err)
+	}
+}
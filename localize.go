@@ -0,0 +1,227 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// Key identifies the string in translation catalogs, the do_objectID of
+// the text layer, or for overrides the do_objectID of the symbol instance
+// and the override name, with `/` replaced by `.` to keep it an XML name token
+func (t *TextEntry) Key() string {
+	if t.OverrideName == "" {
+		return t.ObjectID
+	}
+	return t.ObjectID + ":" + strings.ReplaceAll(t.OverrideName, "/", ".")
+}
+
+// Translation is the translation of one string of the document
+type Translation struct {
+	Key    string
+	Source string
+	// Target is the translated string, empty when not translated yet
+	Target string
+	// Note describes where the string is used
+	Note string
+}
+
+// Catalog holds the translations of the strings of a document to a locale
+type Catalog struct {
+	SourceLocale string
+	Locale       string
+	Translations []*Translation
+}
+
+// NewCatalog returns a catalog of texts to translate from
+// the source locale to locale, with every target empty
+func NewCatalog(texts []*TextEntry, source, locale string) *Catalog {
+	c := &Catalog{SourceLocale: source, Locale: locale}
+	for _, t := range texts {
+		note := t.Page + "/" + strings.Join(t.Path, "/")
+		c.Translations = append(c.Translations, &Translation{
+			Key:    t.Key(),
+			Source: t.Text,
+			Note:   note,
+		})
+	}
+	return c
+}
+
+// LocalizeReport lists the problems found localizing a file
+type LocalizeReport struct {
+	// Missing lists the strings without a translation,
+	// they keep the source text
+	Missing []*TextEntry
+	// Stale lists the strings translated from a source text that
+	// changed since, the translation is applied regardless
+	Stale []*TextEntry
+	// Unknown lists the keys of translations matching no string of the file
+	Unknown []string
+	// Flattened lists the translated text layers styled by several runs,
+	// the whole translation takes the style of the first run
+	Flattened []*TextEntry
+}
+
+// Localize returns a copy of the file with the text layers and text
+// overrides translated by c, the file itself is left unchanged
+func (f *File) Localize(c *Catalog) (*File, *LocalizeReport, error) {
+	translations := map[string]*Translation{}
+	for _, t := range c.Translations {
+		translations[t.Key] = t
+	}
+
+	report := &LocalizeReport{}
+	targets := map[string]string{}
+	entries := map[string]*TextEntry{}
+	for _, text := range f.Texts() {
+		key := text.Key()
+		entries[key] = text
+
+		t, ok := translations[key]
+		if !ok || t.Target == "" {
+			report.Missing = append(report.Missing, text)
+			continue
+		}
+		if t.Source != "" && t.Source != text.Text {
+			report.Stale = append(report.Stale, text)
+		}
+		targets[key] = t.Target
+	}
+	for _, t := range c.Translations {
+		if entries[t.Key] == nil {
+			report.Unknown = append(report.Unknown, t.Key)
+		}
+	}
+
+	out, err := f.clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, page := range out.Pages {
+		if page == nil {
+			continue
+		}
+		err := walkLayers(page.Layers, func(layer Layer) error {
+			flattened, err := localizeLayer(layer, targets)
+			if flattened {
+				report.Flattened = append(report.Flattened, entries[layer.Base().DoObjectID])
+			}
+			return err
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return out, report, nil
+}
+
+// localizeLayer translates the text of the layer or its text overrides,
+// and returns whether the styling of a text layer was flattened
+func localizeLayer(layer Layer, targets map[string]string) (bool, error) {
+	switch l := layer.(type) {
+	case *Text:
+		target, ok := targets[(&TextEntry{ObjectID: l.DoObjectID}).Key()]
+		if !ok || l.AttributedString == nil {
+			return false, nil
+		}
+		flattened, err := l.setString(target)
+		return flattened, errors.Wrapf(err, "text %s", l.DoObjectID)
+	case *SymbolInstance:
+		for _, ov := range l.OverrideValues {
+			if ov == nil {
+				continue
+			}
+			key := (&TextEntry{ObjectID: l.DoObjectID, OverrideName: ov.OverrideName}).Key()
+			target, ok := targets[key]
+			if !ok {
+				continue
+			}
+			ov.Value = target
+			if l.Overrides != nil {
+				setLegacyOverride(*l.Overrides, ov.OverrideName, target)
+			}
+		}
+	}
+	return false, nil
+}
+
+// setLegacyOverride sets the text override of the nested overrides
+// map of documents before Sketch 53, when the map holds it
+func setLegacyOverride(overrides Overrides, name, text string) {
+	m, _ := overrides["0"].(map[string]interface{})
+	ids := strings.Split(strings.TrimSuffix(name, "_stringValue"), "/")
+	for _, id := range ids[:len(ids)-1] {
+		m, _ = m[id].(map[string]interface{})
+	}
+	if _, ok := m[ids[len(ids)-1]].(string); ok {
+		m[ids[len(ids)-1]] = text
+	}
+}
+
+// setString replaces the text of the layer, styling all of it like the
+// start of the current text. It returns whether the current text was
+// styled by several runs, whose styling is lost
+func (t *Text) setString(text string) (bool, error) {
+	s, err := t.attributedString()
+	if err != nil {
+		return false, err
+	}
+	flattened := len(s.Runs) > 1
+
+	s.Text = text
+	s.Runs = s.Runs[:min(len(s.Runs), 1)]
+	if units := len(utf16.Encode([]rune(text))); units == 0 {
+		s.Runs = nil
+	} else if len(s.Runs) == 1 {
+		s.Runs[0].Location = 0
+		s.Runs[0].Length = units
+	}
+	return flattened, t.SetAttributedString(s)
+}
+
+// clone returns a deep copy of the file, going through JSON
+func (f *File) clone() (*File, error) {
+	out := &File{
+		Images:   maps.Clone(f.Images),
+		Previews: maps.Clone(f.Previews),
+		Warnings: slices.Clone(f.Warnings),
+	}
+
+	if err := copyJSON(&f.Document, &out.Document); err != nil {
+		return nil, err
+	}
+	if err := copyJSON(&f.Meta, &out.Meta); err != nil {
+		return nil, err
+	}
+	if err := copyJSON(&f.User, &out.User); err != nil {
+		return nil, err
+	}
+	for _, page := range f.Pages {
+		if page == nil {
+			out.Pages = append(out.Pages, nil)
+			continue
+		}
+		p := &Page{}
+		if err := copyJSON(page, p); err != nil {
+			return nil, err
+		}
+		out.Pages = append(out.Pages, p)
+	}
+	return out, nil
+}
+
+func copyJSON(src, dst interface{}) error {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(src); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf.Bytes(), dst)
+}
@@ -0,0 +1,164 @@
+package sketch
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testFont(name string) *FontDescriptor {
+	return &FontDescriptor{
+		Class:      "fontDescriptor",
+		Attributes: &FontDescriptorAttributes{Name: name, Size: "14"},
+	}
+}
+
+func TestLocalizeFlattensRuns(t *testing.T) {
+	f := &File{Pages: []*Page{{
+		Class:      "page",
+		DoObjectID: "P1",
+		Name:       "Page 1",
+		Layers: Layers{&Text{
+			LayerBase: LayerBase{Class: "text", DoObjectID: "T1", Name: "Title"},
+			AttributedString: &MSAttributedString{
+				Class:  "attributedString",
+				String: "Hello World",
+				Attributes: []*StringAttribute{
+					{Class: "stringAttribute", Location: 0, Length: 6, Attributes: &EncodedAttributes{
+						MSAttributedStringFontAttribute: testFont("Helvetica-Bold"),
+						TextStyleVerticalAlignmentKey:   1,
+					}},
+					{Class: "stringAttribute", Location: 6, Length: 5, Attributes: &EncodedAttributes{
+						MSAttributedStringFontAttribute: testFont("Helvetica"),
+					}},
+				},
+			},
+		}},
+	}}}
+
+	c := NewCatalog(f.Texts(), "en", "fr")
+	c.Translations[0].Target = "Bonjour le monde"
+	c.Translations = append(c.Translations, &Translation{Key: "gone", Target: "Parti"})
+
+	out, report, err := f.Localize(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Flattened) != 1 || report.Flattened[0].ObjectID != "T1" {
+		t.Fatalf("got flattened %v, want T1", report.Flattened)
+	}
+	if !reflect.DeepEqual(report.Unknown, []string{"gone"}) {
+		t.Fatalf("got unknown %v, want [gone]", report.Unknown)
+	}
+
+	as := out.Pages[0].Layers[0].(*Text).AttributedString
+	if as.String != "Bonjour le monde" || len(as.Attributes) != 1 {
+		t.Fatalf("got %q with %d attributes, want the translation in one run", as.String, len(as.Attributes))
+	}
+	attr := as.Attributes[0]
+	if attr.Location != 0 || attr.Length != 16 {
+		t.Fatalf("got run %d+%d, want 0+16", attr.Location, attr.Length)
+	}
+	if attr.Attributes.MSAttributedStringFontAttribute.Attributes.Name != "Helvetica-Bold" || attr.Attributes.TextStyleVerticalAlignmentKey != 1 {
+		t.Fatalf("got attributes %+v, want those of the first run", attr.Attributes)
+	}
+
+	if s := f.Pages[0].Layers[0].(*Text).AttributedString.String; s != "Hello World" {
+		t.Fatalf("source file changed to %q", s)
+	}
+}
+
+func testCatalog() *Catalog {
+	return &Catalog{
+		SourceLocale: "en",
+		Locale:       "de",
+		Translations: []*Translation{
+			{Key: "T1", Source: "Hello", Target: "Hallo", Note: "Page 1/Home/Title"},
+			{Key: "T2", Source: "Line 1\nLine 2\n", Target: "Zeile 1\nZeile 2\n"},
+			{Key: "I1:T3.T4", Source: "Say \"hi\"\tC:\\path", Target: "Sag \"hallo\"\tC:\\Pfad"},
+			{Key: "T5", Source: "<b>&amp;</b>"},
+		},
+	}
+}
+
+func TestPORoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WritePO(buf, testCatalog()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "msgid \"\"\n\"Line 1\\n\"\n\"Line 2\\n\"\n") {
+		t.Fatalf("multi-line string not split after newlines:\n%s", buf)
+	}
+
+	got, err := ReadPO(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testCatalog()) {
+		t.Fatalf("got %+v, want %+v", got, testCatalog())
+	}
+}
+
+func TestReadPOFuzzy(t *testing.T) {
+	po := `msgid ""
+msgstr ""
+"Language: de\n"
+"X-Source-Language: en\n"
+
+#. Page 1/Title
+#, fuzzy
+msgctxt "T1"
+msgid "Hello"
+msgstr "Hallo"
+
+# translator comment
+msgctxt "T2"
+msgid ""
+"Line 1\n"
+"Line 2"
+msgstr "Zeile 1\nZeile 2"
+`
+	got, err := ReadPO(strings.NewReader(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Catalog{
+		SourceLocale: "en",
+		Locale:       "de",
+		Translations: []*Translation{
+			{Key: "T1", Source: "Hello", Note: "Page 1/Title"},
+			{Key: "T2", Source: "Line 1\nLine 2", Target: "Zeile 1\nZeile 2"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := ReadPO(strings.NewReader("msgid \"unterminated\n")); err == nil {
+		t.Fatal("no error for an unterminated string")
+	}
+}
+
+func TestXLIFFRoundTrip(t *testing.T) {
+	for name, write := range map[string]func(*bytes.Buffer, *Catalog) error{
+		"1.2": func(b *bytes.Buffer, c *Catalog) error { return WriteXLIFF12(b, c) },
+		"2.0": func(b *bytes.Buffer, c *Catalog) error { return WriteXLIFF20(b, c) },
+	} {
+		buf := &bytes.Buffer{}
+		if err := write(buf, testCatalog()); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadXLIFF(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, testCatalog()) {
+			t.Errorf("XLIFF %s: got %+v, want %+v", name, got, testCatalog())
+		}
+	}
+
+	if _, err := ReadXLIFF(strings.NewReader(`<xliff version="3.0"></xliff>`)); err == nil {
+		t.Fatal("no error for an unsupported version")
+	}
+}
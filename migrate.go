@@ -0,0 +1,326 @@
+package sketch
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// migration upgrades values decoded from documents older than version
+// to the current model. Migrations only fill the current properties from
+// the legacy ones, so they are safe to apply to current documents
+type migration struct {
+	version int64
+	name    string
+	layer   func(Layer) error
+	style   func(*Style) error
+}
+
+var migrations = []migration{
+	{version: 101, name: "archived text", layer: migrateArchivedText, style: migrateTextStyle},
+	{version: 112, name: "shape path", layer: migrateShapePath},
+	{version: 114, name: "override values", layer: migrateOverrides},
+}
+
+// migrator applies the migrations needed by a document of a format version
+type migrator struct {
+	steps []migration
+}
+
+// migrationRun migrates a single entry, warn is called for layers
+// that failed to migrate when parsing should carry on regardless
+type migrationRun struct {
+	*migrator
+	warn func(error)
+}
+
+func newMigrator(meta *Meta) *migrator {
+	version, _ := meta.Version.Int64()
+
+	m := &migrator{}
+	for _, step := range migrations {
+		if version < step.version {
+			m.steps = append(m.steps, step)
+		}
+	}
+	return m
+}
+
+// document migrates the shared styles and symbols of the document
+func (m *migrator) document(doc *Document, warn func(error)) error {
+	if len(m.steps) == 0 {
+		return nil
+	}
+	r := migrationRun{m, warn}
+
+	if doc.LayerStyles != nil {
+		for _, s := range doc.LayerStyles.Objects {
+			if err := r.sharedStyle(s); err != nil {
+				return err
+			}
+		}
+	}
+	if doc.LayerTextStyles != nil {
+		for _, s := range doc.LayerTextStyles.Objects {
+			if err := r.sharedStyle(s); err != nil {
+				return err
+			}
+		}
+	}
+	if doc.LayerSymbols != nil {
+		if err := r.layers(doc.LayerSymbols.Objects); err != nil {
+			return err
+		}
+	}
+	for _, fs := range doc.ForeignSymbols {
+		if fs == nil {
+			continue
+		}
+		for _, master := range []*SymbolMaster{fs.OriginalMaster, fs.SymbolMaster} {
+			if master != nil {
+				if err := r.layers(Layers{master}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// page migrates every layer of the page
+func (m *migrator) page(page *Page, warn func(error)) error {
+	if len(m.steps) == 0 {
+		return nil
+	}
+	r := migrationRun{m, warn}
+
+	if err := r.styles(page.Style); err != nil {
+		return err
+	}
+	return r.layers(page.Layers)
+}
+
+func (r migrationRun) layers(layers Layers) error {
+	return walkLayers(layers, func(layer Layer) error {
+		if err := r.layer(layer); err != nil {
+			err = &pathError{objectID: layer.Base().DoObjectID, err: err}
+			if r.warn == nil {
+				return err
+			}
+			r.warn(err)
+		}
+		return nil
+	})
+}
+
+func (r migrationRun) layer(layer Layer) error {
+	if err := r.styles(layer.Base().Style); err != nil {
+		return err
+	}
+	for _, step := range r.steps {
+		if step.layer == nil {
+			continue
+		}
+		if err := step.layer(layer); err != nil {
+			return errors.Wrap(err, step.name)
+		}
+	}
+	return nil
+}
+
+func (r migrationRun) sharedStyle(s *SharedStyle) error {
+	if s == nil {
+		return nil
+	}
+	if err := r.styles(s.Value); err != nil {
+		return errors.Wrapf(err, "shared style %s", s.DoObjectID)
+	}
+	return nil
+}
+
+func (r migrationRun) styles(s *Style) error {
+	if s == nil {
+		return nil
+	}
+	for _, step := range r.steps {
+		if step.style == nil {
+			continue
+		}
+		if err := step.style(s); err != nil {
+			return errors.Wrap(err, step.name)
+		}
+	}
+	return nil
+}
+
+// migrateArchivedText fills the string and attributes of text layers
+// from the NSKeyedArchiver archive of Sketch 43 to 47
+func migrateArchivedText(layer Layer) error {
+	text, ok := layer.(*Text)
+	if !ok || text.AttributedString == nil {
+		return nil
+	}
+
+	as := text.AttributedString
+	if as.ArchivedAttributedString == nil || (as.String != "" && as.Attributes != nil) {
+		return nil
+	}
+
+	s, err := as.ArchivedAttributedString.AttributedString()
+	if err != nil {
+		return err
+	}
+	if as.String == "" {
+		as.String = s.Text
+	}
+	if as.Attributes == nil {
+		as.Attributes = stringAttributes(s.Runs)
+	}
+	return nil
+}
+
+func stringAttributes(runs []AttributeRun) []*StringAttribute {
+	attrs := make([]*StringAttribute, 0, len(runs))
+	for _, run := range runs {
+		attrs = append(attrs, &StringAttribute{
+			Class:    "stringAttribute",
+			Location: int64(run.Location),
+			Length:   int64(run.Length),
+			Attributes: &EncodedAttributes{
+				Kerning:                                  run.Kerning,
+				MSAttributedStringColorAttribute:         run.Color,
+				MSAttributedStringFontAttribute:          run.Font,
+				ParagraphStyle:                           run.ParagraphStyle,
+				StrikethroughStyle:                       int64(run.Strikethrough),
+				UnderlineStyle:                           int64(run.Underline),
+				MSAttributedStringTextTransformAttribute: run.TextTransform,
+			},
+		})
+	}
+	return attrs
+}
+
+// migrateTextStyle decodes the archived attributes of text styles,
+// and copies the NS prefixed attributes to the properties used since Sketch 48
+func migrateTextStyle(s *Style) error {
+	if s.TextStyle == nil || s.TextStyle.EncodedAttributes == nil {
+		return nil
+	}
+
+	attrs := s.TextStyle.EncodedAttributes
+	if err := unarchiveFont(attrs.MSAttributedStringFontAttribute); err != nil {
+		return errors.Wrap(err, "font")
+	}
+	for _, c := range []*Color{attrs.NSColor, attrs.NSStrokeColor} {
+		if err := unarchiveColor(c); err != nil {
+			return errors.Wrap(err, "color")
+		}
+	}
+	if err := unarchiveParagraphStyle(attrs.NSParagraphStyle); err != nil {
+		return errors.Wrap(err, "paragraph style")
+	}
+
+	if attrs.Kerning == 0 {
+		attrs.Kerning = attrs.NSKern
+	}
+	if attrs.UnderlineStyle == 0 {
+		attrs.UnderlineStyle = attrs.NSUnderline
+	}
+	if attrs.StrikethroughStyle == 0 {
+		attrs.StrikethroughStyle = attrs.NSStrikethrough
+	}
+	if attrs.MSAttributedStringColorAttribute == nil && attrs.NSColor != nil && attrs.NSColor.Class != "" {
+		attrs.MSAttributedStringColorAttribute = &Color{
+			Class: attrs.NSColor.Class,
+			Alpha: attrs.NSColor.Alpha,
+			Blue:  attrs.NSColor.Blue,
+			Green: attrs.NSColor.Green,
+			Red:   attrs.NSColor.Red,
+		}
+	}
+	if attrs.ParagraphStyle == nil && attrs.NSParagraphStyle != nil {
+		p := attrs.NSParagraphStyle
+		attrs.ParagraphStyle = &ParagraphStyle{
+			Class:             p.Class,
+			Alignment:         p.Alignment,
+			MaximumLineHeight: p.MaximumLineHeight,
+			MinimumLineHeight: p.MinimumLineHeight,
+			ParagraphSpacing:  p.ParagraphSpacing,
+		}
+	}
+	return nil
+}
+
+// migrateShapePath moves the points of shapes from the path
+// object to the layer itself, as stored since Sketch 52
+func migrateShapePath(layer Layer) error {
+	var shape *ShapeBase
+	switch l := layer.(type) {
+	case *Rectangle:
+		shape = &l.ShapeBase
+	case *Oval:
+		shape = &l.ShapeBase
+	case *ShapePath:
+		shape = &l.ShapeBase
+	case *Star:
+		shape = &l.ShapeBase
+	case *Polygon:
+		shape = &l.ShapeBase
+	case *Triangle:
+		shape = &l.ShapeBase
+	default:
+		return nil
+	}
+
+	if shape.Path == nil || shape.Points != nil {
+		return nil
+	}
+	shape.Points = shape.Path.Points
+	shape.IsClosed = shape.Path.IsClosed
+	return nil
+}
+
+// migrateOverrides converts the nested overrides map of symbol
+// instances to the flat list of override values used since Sketch 53
+func migrateOverrides(layer Layer) error {
+	inst, ok := layer.(*SymbolInstance)
+	if !ok || inst.Overrides == nil || inst.OverrideValues != nil {
+		return nil
+	}
+
+	// overrides are keyed by the symbol state, Sketch only ever used "0"
+	overrides, _ := (*inst.Overrides)["0"].(map[string]interface{})
+	inst.OverrideValues = overrideValues(nil, overrides)
+	return nil
+}
+
+func overrideValues(path []string, overrides map[string]interface{}) []*OverrideValue {
+	ids := make([]string, 0, len(overrides))
+	for id := range overrides {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	values := []*OverrideValue{}
+	for _, id := range ids {
+		sub := append(append([]string{}, path...), id)
+		name := strings.Join(sub, "/")
+
+		switch v := overrides[id].(type) {
+		case string:
+			values = append(values, &OverrideValue{Class: "overrideValue", OverrideName: name + "_stringValue", Value: v})
+		case map[string]interface{}:
+			if symbolID, ok := v["symbolID"].(string); ok {
+				values = append(values, &OverrideValue{Class: "overrideValue", OverrideName: name + "_symbolID", Value: symbolID})
+				continue
+			}
+			if v["_class"] == "MSJSONFileReference" {
+				values = append(values, &OverrideValue{Class: "overrideValue", OverrideName: name + "_image", Value: v})
+				continue
+			}
+			values = append(values, overrideValues(sub, v)...)
+		}
+	}
+	return values
+}
@@ -0,0 +1,241 @@
+package sketch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"howett.net/plist"
+)
+
+// migratedModel holds the properties the migrations fill, in plain values
+// so documents of different versions can be compared
+type migratedModel struct {
+	Text      string
+	Runs      []runModel
+	TextStyle runModel
+	IsClosed  bool
+	Points    []string
+	Overrides map[string]interface{}
+}
+
+type runModel struct {
+	Location, Length int64
+	Font             string
+	Size             float64
+	Color            [4]float64
+	Kerning          float64
+	Alignment        float64
+	LineHeight       [2]float64
+	Spacing          float64
+	Underline        int64
+}
+
+func number(t *testing.T, n json.Number) float64 {
+	t.Helper()
+	if n == "" {
+		return 0
+	}
+	f, err := n.Float64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func newRunModel(t *testing.T, location, length int64, a *EncodedAttributes) runModel {
+	t.Helper()
+	r := runModel{Location: location, Length: length, Kerning: a.Kerning, Underline: a.UnderlineStyle}
+	if f := a.MSAttributedStringFontAttribute; f != nil && f.Attributes != nil {
+		r.Font = f.Attributes.Name
+		r.Size = number(t, f.Attributes.Size)
+	}
+	if c := a.MSAttributedStringColorAttribute; c != nil {
+		r.Color = [4]float64{number(t, c.Red), number(t, c.Green), number(t, c.Blue), number(t, c.Alpha)}
+	}
+	if p := a.ParagraphStyle; p != nil {
+		r.Alignment = number(t, p.Alignment)
+		r.LineHeight = [2]float64{number(t, p.MinimumLineHeight), number(t, p.MaximumLineHeight)}
+		r.Spacing = number(t, p.ParagraphSpacing)
+	}
+	return r
+}
+
+func newMigratedModel(t *testing.T, f *File) *migratedModel {
+	t.Helper()
+	m := &migratedModel{Overrides: map[string]interface{}{}}
+
+	layers := f.Pages[0].Layers[0].(*Artboard).Layers
+	text := layers[0].(*Text)
+	m.Text = text.AttributedString.String
+	for _, a := range text.AttributedString.Attributes {
+		m.Runs = append(m.Runs, newRunModel(t, a.Location, a.Length, a.Attributes))
+	}
+
+	style := f.Document.LayerTextStyles.Objects[0].Value
+	m.TextStyle = newRunModel(t, 0, 0, style.TextStyle.EncodedAttributes)
+
+	oval := layers[1].(*Oval)
+	m.IsClosed = oval.IsClosed
+	for _, p := range oval.Points {
+		m.Points = append(m.Points, p.Point.String(), p.CurveFrom.String(), p.CurveTo.String())
+	}
+
+	for _, ov := range layers[2].(*SymbolInstance).OverrideValues {
+		m.Overrides[ov.OverrideName] = ov.Value
+	}
+	return m
+}
+
+func TestMigrations(t *testing.T) {
+	current, err := Parse("testdata/v146")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := newMigratedModel(t, current)
+	if want.Text != "Hello World" || len(want.Runs) != 2 || len(want.Points) != 6 || len(want.Overrides) != 2 {
+		t.Fatalf("unexpected current fixture %+v", want)
+	}
+
+	// each fixture is in the format of its version, with the text,
+	// the oval and the symbol instance of the v146 fixture
+	tests := []struct {
+		dir   string
+		steps []string
+	}{
+		{"testdata/v88", []string{"archived text", "shape path", "override values"}},
+		{"testdata/v109", []string{"shape path", "override values"}},
+		{"testdata/v112", []string{"override values"}},
+	}
+	for _, tt := range tests {
+		legacy, err := Parse(tt.dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(legacy.Warnings) > 0 {
+			t.Fatalf("%s: migration warnings %v", tt.dir, legacy.Warnings)
+		}
+
+		steps := []string{}
+		for _, step := range newMigrator(&legacy.Meta).steps {
+			steps = append(steps, step.name)
+		}
+		if !reflect.DeepEqual(steps, tt.steps) {
+			t.Fatalf("%s: got migrations %q, want %q", tt.dir, steps, tt.steps)
+		}
+
+		if got := newMigratedModel(t, legacy); !reflect.DeepEqual(got, want) {
+			t.Fatalf("migrated %s fixture\ngot  %+v\nwant %+v", tt.dir, got, want)
+		}
+	}
+
+	legacy, err := Parse("testdata/v88")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := legacy.Pages[0].Layers[0].(*Artboard).Layers[0].(*Text)
+	if text.AttributedString.ArchivedAttributedString == nil {
+		t.Fatal("archive of the legacy text dropped")
+	}
+}
+
+func TestMigrationsSkippedForCurrentVersion(t *testing.T) {
+	if steps := newMigrator(&Meta{Version: "146"}).steps; len(steps) != 0 {
+		t.Fatalf("got %d migrations for the current version", len(steps))
+	}
+	if steps := newMigrator(&Meta{Version: "88"}).steps; len(steps) != len(migrations) {
+		t.Fatalf("got %d migrations for version 88, want %d", len(steps), len(migrations))
+	}
+}
+
+// archivedStyle is a text style whose font, colors and paragraph
+// style are archived, as written before Sketch 48
+func archivedStyle(t *testing.T, fontName string) *Style {
+	t.Helper()
+	archive := func(root map[string]interface{}, classes ...string) *Archive {
+		a := &Archive{}
+		a.SetData(newKeyedArchiver().archive(root, classes...))
+		return a
+	}
+
+	k := newKeyedArchiver()
+	desc := k.dict([]string{"NSFontNameAttribute", "NSFontSizeAttribute"}, []plist.UID{k.add(fontName), k.add(14.0)})
+	font := &Archive{}
+	font.SetData(k.archive(map[string]interface{}{"NSFontDescriptorAttributes": desc}, "NSFontDescriptor", "NSObject"))
+
+	s := &Style{TextStyle: &TextStyle{EncodedAttributes: &EncodedAttributes{
+		MSAttributedStringFontAttribute: &FontDescriptor{Archive: font},
+		NSColor: &Color{Archive: archive(map[string]interface{}{
+			"NSColorSpace": int64(3),
+			"NSWhite":      []byte("0.5 0.8\x00"),
+		}, "NSColor", "NSObject")},
+		NSStrokeColor: &Color{Archive: archive(map[string]interface{}{
+			"NSColorSpace": int64(1),
+			"NSRGB":        []byte("1 0 0\x00"),
+		}, "NSColor", "NSObject")},
+		NSParagraphStyle: &ParagraphStyle{Archive: archive(map[string]interface{}{
+			"NSAlignment":        int64(2),
+			"NSParagraphSpacing": 8.0,
+		}, "NSMutableParagraphStyle", "NSParagraphStyle", "NSObject")},
+		NSKern:      1.5,
+		NSUnderline: 1,
+	}}}
+
+	// decode the style as read from a document
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Style{}
+	if err := json.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestMigrateTextStyle(t *testing.T) {
+	s := archivedStyle(t, "Inter-Medium")
+	if err := migrateTextStyle(s); err != nil {
+		t.Fatal(err)
+	}
+
+	attrs := s.TextStyle.EncodedAttributes
+	grey := &Color{Class: "color", Red: "0.5", Green: "0.5", Blue: "0.5", Alpha: "0.8"}
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"font", attrs.MSAttributedStringFontAttribute.Attributes, &FontDescriptorAttributes{Name: "Inter-Medium", Size: "14"}},
+		{"NSColor", attrs.NSColor.Class + " " + string(attrs.NSColor.Red) + " " + string(attrs.NSColor.Alpha), "color 0.5 0.8"},
+		{"NSStrokeColor", attrs.NSStrokeColor.Class + " " + string(attrs.NSStrokeColor.Red) + " " + string(attrs.NSStrokeColor.Blue), "color 1 0"},
+		{"color", attrs.MSAttributedStringColorAttribute, grey},
+		{"paragraph style", attrs.ParagraphStyle, &ParagraphStyle{Class: "paragraphStyle", Alignment: "2", ParagraphSpacing: "8"}},
+		{"kerning", attrs.Kerning, 1.5},
+		{"underline", attrs.UnderlineStyle, int64(1)},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+	if attrs.NSParagraphStyle.Archive == nil || attrs.MSAttributedStringFontAttribute.Archive == nil {
+		t.Error("archives dropped by the migration")
+	}
+
+	// properties already set are kept
+	s = archivedStyle(t, "Inter-Medium")
+	attrs = s.TextStyle.EncodedAttributes
+	attrs.Kerning = 3
+	attrs.MSAttributedStringColorAttribute = &Color{Class: "color", Red: "0", Green: "0", Blue: "0", Alpha: "1"}
+	if err := migrateTextStyle(s); err != nil {
+		t.Fatal(err)
+	}
+	if attrs.Kerning != 3 || attrs.MSAttributedStringColorAttribute.Alpha != "1" {
+		t.Errorf("got kerning %v and color %+v, want the ones set", attrs.Kerning, attrs.MSAttributedStringColorAttribute)
+	}
+
+	if err := migrateTextStyle(archivedStyle(t, "")); err == nil || !strings.HasPrefix(err.Error(), "font: ") {
+		t.Errorf("got %v, want an error for the font without a name", err)
+	}
+}
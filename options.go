@@ -0,0 +1,206 @@
+package sketch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseMode selects how documents that do not match the model are handled
+type ParseMode int
+
+const (
+	// ParseDefault fails on layers that cannot be decoded,
+	// layers of an unknown class are kept as UnknownLayer
+	ParseDefault ParseMode = iota
+	// ParseStrict also fails on layers of an unknown class
+	// and on objects missing required properties
+	ParseStrict
+	// ParseLenient skips layers and pages that cannot be decoded,
+	// recording each of them in File.Warnings
+	ParseLenient
+)
+
+type ParseOptions struct {
+	Mode ParseMode
+
+	// Concurrency is the number of pages decoded in parallel,
+	// runtime.GOMAXPROCS(0) when not positive
+	Concurrency int
+
+	// Pages and Artboards restrict parsing to the pages and top level
+	// artboards matching a do_objectID or a path.Match pattern of the
+	// name. Pages without a matching artboard are skipped when Artboards
+	// is set, everything is parsed when both are empty
+	Pages     []string
+	Artboards []string
+
+	// Limits bound the resources used parsing untrusted files
+	Limits Limits
+}
+
+func (o ParseOptions) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+var (
+	// ErrUnknownClass is returned in strict mode for layers
+	// with a `_class` this package does not model
+	ErrUnknownClass = errors.New("unknown layer class")
+
+	// ErrMissingProperty is returned in strict mode for
+	// objects missing a required property
+	ErrMissingProperty = errors.New("missing required property")
+)
+
+// ParseWithOptions will un-compress a sketch file,
+// and parse the contents as configured by opts
+func ParseWithOptions(src string, opts ParseOptions) (*File, error) {
+	return ParseContext(context.Background(), src, opts)
+}
+
+// ParseContext is ParseWithOptions, stopping early with
+// the error of ctx when ctx is done
+func ParseContext(ctx context.Context, src string, opts ParseOptions) (*File, error) {
+	fsys, closer, err := openFS(src)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	return newParser(fsys, opts).parse(ctx)
+}
+
+// required lists the properties strict mode expects, by `_class`
+var required = map[string][]string{
+	"document":       {"_class", "do_objectID", "pages"},
+	"page":           {"_class", "do_objectID", "name", "layers"},
+	"layer":          {"_class", "do_objectID", "frame"},
+	"artboard":       {"layers"},
+	"bitmap":         {"image"},
+	"group":          {"layers"},
+	"shapeGroup":     {"layers"},
+	"symbolInstance": {"symbolID"},
+	"symbolMaster":   {"layers", "symbolID"},
+	"text":           {"attributedString"},
+}
+
+// missing returns the first property of class that was not in the decoded JSON
+func missing(extra *Extra, classes ...string) string {
+	if extra.present == nil {
+		return ""
+	}
+	for _, class := range classes {
+		for _, name := range required[class] {
+			if !extra.present[name] {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func (p *parser) validateDocument(doc *Document) error {
+	if p.opts.Mode != ParseStrict {
+		return nil
+	}
+
+	if name := missing(&doc.Extra, "document"); name != "" {
+		return &ParseError{Entry: "document.json", Err: errors.Wrapf(ErrMissingProperty, "%q", name)}
+	}
+	if doc.LayerSymbols != nil {
+		return validateLayers("document.json", "/layerSymbols/objects", doc.LayerSymbols.Objects)
+	}
+	return nil
+}
+
+func (p *parser) validatePage(name string, page *Page) error {
+	if p.opts.Mode != ParseStrict {
+		return nil
+	}
+
+	if prop := missing(&page.Extra, "page"); prop != "" {
+		return &ParseError{Entry: name, Err: errors.Wrapf(ErrMissingProperty, "%q", prop)}
+	}
+	return validateLayers(name, "/layers", page.Layers)
+}
+
+// validateLayers checks layers found at pointer in entry recursively
+func validateLayers(entry, pointer string, layers Layers) error {
+	for i, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		base := layer.Base()
+		ptr := pointer + "/" + strconv.Itoa(i)
+
+		if _, ok := layer.(*UnknownLayer); ok {
+			return &ParseError{Entry: entry, Pointer: ptr, ObjectID: base.DoObjectID, Err: errors.Wrapf(ErrUnknownClass, "%q", base.Class)}
+		}
+		if name := missing(&base.Extra, "layer", base.Class); name != "" {
+			return &ParseError{Entry: entry, Pointer: ptr, ObjectID: base.DoObjectID, Err: errors.Wrapf(ErrMissingProperty, "%q", name)}
+		}
+
+		if c, ok := layer.(LayerContainer); ok {
+			if err := validateLayers(entry, ptr+"/layers", c.Children()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nullPointer replaces the array element at the JSON pointer ptr with
+// null, which Layers skips. Keeping the element in place keeps the
+// pointers of later errors valid for the original entry
+func nullPointer(data []byte, ptr string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	root, err := nullElement(root, strings.Split(strings.TrimPrefix(ptr, "/"), "/"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "skip %s", ptr)
+	}
+	return json.Marshal(root)
+}
+
+func nullElement(v interface{}, keys []string) (interface{}, error) {
+	key := strings.NewReplacer("~1", "/", "~0", "~").Replace(keys[0])
+
+	switch x := v.(type) {
+	case map[string]interface{}:
+		child, ok := x[key]
+		if !ok || len(keys) == 1 {
+			return nil, errors.Errorf("no array element at %q", key)
+		}
+		child, err := nullElement(child, keys[1:])
+		x[key] = child
+		return x, err
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(x) {
+			return nil, errors.Errorf("invalid index %q", key)
+		}
+		if len(keys) == 1 {
+			x[i] = nil
+			return x, nil
+		}
+		child, err := nullElement(x[i], keys[1:])
+		x[i] = child
+		return x, err
+	}
+	return nil, errors.Errorf("no value at %q", key)
+}
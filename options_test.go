@@ -0,0 +1,153 @@
+package sketch
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+)
+
+// pageFS is minimalFS with the given layers on its page
+func pageFS(layers ...string) fstest.MapFS {
+	fsys := minimalFS()
+	fsys["pages/P1.json"].Data = []byte(`{"_class":"page","do_objectID":"P1","name":"Page 1","layers":[` + strings.Join(layers, ",") + `]}`)
+	return fsys
+}
+
+func parseMode(fsys fstest.MapFS, mode ParseMode) (*File, error) {
+	return ParseFSContext(context.Background(), fsys, ParseOptions{Mode: mode})
+}
+
+// resolvePointer returns the value at the JSON pointer ptr of data
+func resolvePointer(t *testing.T, data []byte, ptr string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			v = x[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i >= len(x) {
+				t.Fatalf("no element %q in %s", key, ptr)
+			}
+			v = x[i]
+		default:
+			t.Fatalf("no value at %s", ptr)
+		}
+	}
+	return v
+}
+
+func TestStrictUnknownClass(t *testing.T) {
+	fsys := pageFS(`{"_class":"mystery","do_objectID":"M1","frame":{},"sparkles":3}`)
+
+	f, err := parseMode(fsys, ParseDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, ok := f.Pages[0].Layers[0].(*UnknownLayer)
+	if !ok || layer.DoObjectID != "M1" || string(layer.Extra.Fields["sparkles"]) != "3" {
+		t.Fatalf("got %#v, want an UnknownLayer keeping its properties", f.Pages[0].Layers[0])
+	}
+
+	_, err = parseMode(fsys, ParseStrict)
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrUnknownClass) || perr.Entry != "pages/P1.json" || perr.Pointer != "/layers/0" || perr.ObjectID != "M1" {
+		t.Fatalf("got %v, want ErrUnknownClass at pages/P1.json /layers/0", err)
+	}
+}
+
+func TestStrictMissingProperty(t *testing.T) {
+	tests := []struct {
+		fsys    fstest.MapFS
+		entry   string
+		pointer string
+	}{
+		{pageFS(`{"_class":"group","do_objectID":"G1","frame":{},"layers":[{"_class":"rectangle","do_objectID":"R1"}]}`), "pages/P1.json", "/layers/0/layers/0"},
+		{pageFS(`{"_class":"symbolInstance","do_objectID":"I1","frame":{}}`), "pages/P1.json", "/layers/0"},
+		{fstest.MapFS{"document.json": {Data: []byte(`{"_class":"document","pages":[]}`)}}, "document.json", ""},
+	}
+	for _, tt := range tests {
+		if _, err := parseMode(tt.fsys, ParseDefault); err != nil {
+			t.Fatal(err)
+		}
+		_, err := parseMode(tt.fsys, ParseStrict)
+		var perr *ParseError
+		if !errors.As(err, &perr) || !errors.Is(err, ErrMissingProperty) || perr.Entry != tt.entry || perr.Pointer != tt.pointer {
+			t.Errorf("got %v, want ErrMissingProperty at %s %s", err, tt.entry, tt.pointer)
+		}
+	}
+}
+
+func TestLenientSkipsBadLayers(t *testing.T) {
+	fsys := pageFS(
+		`{"_class":"rectangle","do_objectID":"R1","frame":{}}`,
+		`{"_class":"rectangle","do_objectID":"R2","frame":"broken"}`,
+		`{"_class":"group","do_objectID":"G1","frame":{},"layers":[{"_class":"oval","do_objectID":"O1","points":7},{"_class":"oval","do_objectID":"O2","frame":{}}]}`,
+	)
+
+	_, err := parseMode(fsys, ParseDefault)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Pointer != "/layers/1/frame" || perr.ObjectID != "R2" {
+		t.Fatalf("got %v, want the broken frame of R2", err)
+	}
+
+	f, err := parseMode(fsys, ParseLenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers := f.Pages[0].Layers
+	if len(layers) != 2 || layers[0].Base().DoObjectID != "R1" || layers[1].Base().DoObjectID != "G1" {
+		t.Fatalf("got layers %v, want R1 and G1", layers)
+	}
+	if children := layers[1].(*Group).Layers; len(children) != 1 || children[0].Base().DoObjectID != "O2" {
+		t.Fatalf("got group layers %v, want O2", children)
+	}
+
+	want := []struct{ pointer, id string }{{"/layers/1/frame", "R2"}, {"/layers/2/layers/0/points", "O1"}}
+	if len(f.Warnings) != len(want) {
+		t.Fatalf("got warnings %v, want %d", f.Warnings, len(want))
+	}
+	for i, w := range f.Warnings {
+		if w.Entry != "pages/P1.json" || w.Pointer != want[i].pointer || w.ObjectID != want[i].id {
+			t.Errorf("warning %d is %v, want %s at %s", i, w, want[i].id, want[i].pointer)
+		}
+		// the pointer is valid for the entry as stored
+		layer := resolvePointer(t, fsys["pages/P1.json"].Data, w.Pointer[:strings.LastIndex(w.Pointer, "/")])
+		if id := layer.(map[string]interface{})["do_objectID"]; id != w.ObjectID {
+			t.Errorf("warning %d points at layer %v, want %s", i, id, w.ObjectID)
+		}
+	}
+}
+
+func TestLenientHeader(t *testing.T) {
+	fsys := minimalFS()
+	fsys["meta.json"].Data = []byte(`{"version":`)
+	fsys["user.json"] = &fstest.MapFile{Data: []byte(`[1]`)}
+
+	for _, mode := range []ParseMode{ParseDefault, ParseStrict} {
+		var perr *ParseError
+		if _, err := parseMode(fsys, mode); !errors.As(err, &perr) || perr.Entry != "meta.json" {
+			t.Fatalf("mode %d: got %v, want an error decoding meta.json", mode, err)
+		}
+	}
+
+	f, err := parseMode(fsys, ParseLenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Pages) != 1 || f.Meta.Version != "" || f.User.Pages != nil {
+		t.Fatalf("got %d pages, meta %+v and user %+v", len(f.Pages), f.Meta, f.User)
+	}
+	if len(f.Warnings) != 2 || f.Warnings[0].Entry != "meta.json" || f.Warnings[1].Entry != "user.json" {
+		t.Fatalf("got warnings %v, want meta.json and user.json", f.Warnings)
+	}
+}
@@ -2,58 +2,452 @@ package sketch
 
 import (
 	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/json"
-	"strings"
-)
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"sync"
 
-type File struct {
-	Document Document
-	Pages    map[string]Page
-}
+	"github.com/pkg/errors"
+)
 
 // Parse will un-compress a sketch file,
-// and parse the contents
+// and parse the contents. src may also be a directory
+// written by Explode
 func Parse(src string) (*File, error) {
-	sketchFile := File{
-		Pages: map[string]Page{},
+	return ParseWithOptions(src, ParseOptions{})
+}
+
+// openFS opens the sketch file at src as a file system,
+// src is either a sketch archive or an exploded directory
+func openFS(src string) (fs.FS, io.Closer, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(src), f, nil
+	}
+
+	if err := sniff(f); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return zr, f, nil
+}
+
+// ParseReader parses a sketch file of the given size read from r,
+// which is useful when the file was never written to disk
+func ParseReader(r io.ReaderAt, size int64) (*File, error) {
+	return ParseReaderWithOptions(r, size, ParseOptions{})
+}
+
+// ParseReaderWithOptions is ParseReader, parsing as configured by opts
+func ParseReaderWithOptions(r io.ReaderAt, size int64, opts ParseOptions) (*File, error) {
+	return ParseReaderContext(context.Background(), r, size, opts)
+}
+
+// ParseReaderContext is ParseReaderWithOptions, stopping early with
+// the error of ctx when ctx is done
+func ParseReaderContext(ctx context.Context, r io.ReaderAt, size int64, opts ParseOptions) (*File, error) {
+	if err := sniff(r); err != nil {
+		return nil, err
 	}
 
-	zr, err := zip.OpenReader(src)
+	zr, err := zip.NewReader(r, size)
 	if err != nil {
 		return nil, err
 	}
-	defer zr.Close()
 
-	for _, f := range zr.File {
-		name := f.FileHeader.Name
+	return newParser(zr, opts).parse(ctx)
+}
+
+// ParseBytes parses a sketch file held in memory
+func ParseBytes(b []byte) (*File, error) {
+	return ParseReader(bytes.NewReader(b), int64(len(b)))
+}
 
-		if strings.HasPrefix(name, "pages/") {
-			page := Page{}
-			if err := parseObj(f, &page); err != nil {
-				return nil, err
-			}
-			sketchFile.Pages[page.Name] = page
+// ParseFS parses the contents of an already un-compressed sketch file,
+// with document.json and the pages directory at the root of fsys
+func ParseFS(fsys fs.FS) (*File, error) {
+	return ParseFSContext(context.Background(), fsys, ParseOptions{})
+}
+
+// ParseFSContext is ParseFS, parsing as configured by opts and stopping
+// early with the error of ctx when ctx is done
+func ParseFSContext(ctx context.Context, fsys fs.FS, opts ParseOptions) (*File, error) {
+	return newParser(fsys, opts).parse(ctx)
+}
+
+// parser holds the state of parsing one sketch file
+type parser struct {
+	// src is the file system read, fsys enforces the limits on it
+	src     fs.FS
+	fsys    fs.FS
+	opts    ParseOptions
+	migrate *migrator
+}
+
+func newParser(fsys fs.FS, opts ParseOptions) *parser {
+	p := &parser{
+		src:  fsys,
+		fsys: fsys,
+		opts: opts,
+	}
+	if !opts.Limits.zero() {
+		p.fsys = newLimitFS(fsys, opts.Limits)
+	}
+	return p
+}
+
+func (p *parser) parse(ctx context.Context) (*File, error) {
+	sketchFile, names, err := p.header()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := p.pages(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if r.page != nil {
+			sketchFile.Pages = append(sketchFile.Pages, r.page)
 		}
+		sketchFile.Warnings = append(sketchFile.Warnings, r.warnings...)
+	}
+
+	if sketchFile.Images, err = readFiles(p.fsys, "images"); err != nil {
+		return nil, err
+	}
+	if sketchFile.Previews, err = readFiles(p.fsys, "previews"); err != nil {
+		return nil, err
+	}
+	return sketchFile, nil
+}
+
+// header parses the entries of the file besides the pages,
+// and returns the names of the page entries to decode
+func (p *parser) header() (*File, []string, error) {
+	if err := checkEntries(p.src, p.opts.Limits); err != nil {
+		return nil, nil, err
+	}
+
+	sketchFile := &File{}
+
+	meta := Meta{}
+	e := p.entry("meta.json")
+	err := e.decodeOptional(&meta)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	sketchFile.Meta = meta
+	sketchFile.Warnings = append(sketchFile.Warnings, e.warnings...)
+
+	if err := checkVersion(&meta); err != nil {
+		return nil, nil, err
+	}
+	p.migrate = newMigrator(&meta)
+
+	doc := Document{}
+	e = p.entry("document.json")
+	err = e.decode(&doc)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	if err := p.migrate.document(&doc, e.warner()); err != nil {
+		return nil, nil, newParseError(e.name, err)
+	}
+	if err := p.validateDocument(&doc); err != nil {
+		return nil, nil, err
+	}
+	sketchFile.Document = doc
+	sketchFile.Warnings = append(sketchFile.Warnings, e.warnings...)
+
+	user := UserState{}
+	e = p.entry("user.json")
+	err = e.decodeOptional(&user)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	sketchFile.User = user
+	sketchFile.Warnings = append(sketchFile.Warnings, e.warnings...)
+
+	names, unreferenced, err := pageEntries(p.fsys, doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range unreferenced {
+		sketchFile.Warnings = append(sketchFile.Warnings, newParseError(name, ErrUnreferencedPage))
+	}
+	names, err = p.selectPages(&meta, names)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sketchFile, names, nil
+}
+
+type pageResult struct {
+	page     *Page
+	warnings []*ParseError
+	err      error
+}
+
+// pages decodes the page entries on up to Concurrency goroutines.
+// Results are in the order of names, and the error returned is the one
+// of the first failing page, as if the pages were decoded in order
+func (p *parser) pages(ctx context.Context, names []string) ([]pageResult, error) {
+	results := make([]pageResult, len(names))
+
+	var mu sync.Mutex
+	failed := len(names)
 
-		if name == "document.json" {
-			doc := Document{}
-			if err := parseObj(f, &doc); err != nil {
-				return nil, err
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(p.opts.concurrency(), len(names)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				skip := i > failed
+				mu.Unlock()
+				if skip || ctx.Err() != nil {
+					continue
+				}
+
+				e := p.entry(names[i])
+				page, err := e.page()
+				results[i] = pageResult{page: page, warnings: e.warnings, err: err}
+
+				if err != nil {
+					mu.Lock()
+					failed = min(failed, i)
+					mu.Unlock()
+				}
 			}
-			sketchFile.Document = doc
+		}()
+	}
+
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if r.err != nil {
+			return nil, r.err
 		}
 	}
-	return &sketchFile, nil
+	return results, nil
+}
+
+// entry is the decoding of a single JSON entry of the file
+type entry struct {
+	*parser
+	name     string
+	warnings []*ParseError
 }
 
-func parseObj(file *zip.File, dst interface{}) error {
-	f, err := file.Open()
+func (p *parser) entry(name string) *entry {
+	return &entry{parser: p, name: name}
+}
+
+// page decodes, migrates and validates the page stored in the entry.
+// A nil page is returned when no artboard of the page is selected, and
+// in lenient mode when the page cannot be decoded at all
+func (e *entry) page() (*Page, error) {
+	data, err := e.read()
+	if err != nil {
+		return nil, err
+	}
+	data, selected, err := e.selectLayers(data)
+	if err != nil {
+		return nil, newParseError(e.name, err)
+	}
+	if !selected {
+		return nil, nil
+	}
+
+	page := &Page{}
+	if err := e.decodeData(data, page); err != nil {
+		var perr *ParseError
+		if e.opts.Mode == ParseLenient && errors.As(err, &perr) {
+			e.warn(perr)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := e.migrate.page(page, e.warner()); err != nil {
+		return nil, newParseError(e.name, err)
+	}
+	if err := e.validatePage(e.name, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// decode decodes the JSON of the entry into dst. In lenient mode layers
+// that fail to decode are removed and reported as warnings
+func (e *entry) decode(dst interface{}) error {
+	data, err := e.read()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	if err := json.NewDecoder(f).Decode(dst); err != nil {
+	return e.decodeData(data, dst)
+}
+
+// decodeOptional is decode for the entries a document can do without.
+// In lenient mode an entry that cannot be decoded is recorded as a
+// warning and dst is left empty, errors reading it are still returned
+func (e *entry) decodeOptional(dst interface{}) error {
+	data, err := e.read()
+	if err != nil {
 		return err
 	}
-	return nil
+
+	err = e.decodeData(data, dst)
+	var perr *ParseError
+	if err != nil && e.opts.Mode == ParseLenient && errors.As(err, &perr) {
+		reflect.ValueOf(dst).Elem().SetZero()
+		e.warn(perr)
+		return nil
+	}
+	return err
+}
+
+// read reads the entry, checking the nesting of its layers
+func (e *entry) read() ([]byte, error) {
+	data, err := fs.ReadFile(e.fsys, e.name)
+	if err != nil {
+		return nil, err
+	}
+	if max := e.opts.Limits.MaxLayerDepth; max > 0 {
+		if err := checkLayerDepth(e.name, data, max); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (e *entry) decodeData(data []byte, dst interface{}) error {
+	for {
+		reflect.ValueOf(dst).Elem().SetZero()
+		err := json.Unmarshal(data, dst)
+		if err == nil {
+			return nil
+		}
+
+		perr := newParseError(e.name, err)
+		if e.opts.Mode != ParseLenient || perr.layer == "" {
+			return perr
+		}
+
+		data, err = nullPointer(data, perr.layer)
+		if err != nil {
+			return perr
+		}
+		e.warn(perr)
+	}
+}
+
+// warner returns the function migrations report recoverable
+// problems to, or nil when they are not recoverable
+func (e *entry) warner() func(error) {
+	if e.opts.Mode != ParseLenient {
+		return nil
+	}
+	return func(err error) {
+		e.warn(newParseError(e.name, err))
+	}
+}
+
+// warn records a problem that lenient mode recovered from
+func (e *entry) warn(err *ParseError) {
+	e.warnings = append(e.warnings, err)
+}
+
+// readFiles reads every file below dir, keyed by the path within fsys
+func readFiles(fsys fs.FS, dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		files[name] = b
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// ErrUnreferencedPage is the warning for a page entry that the page
+// references of document.json do not list, the page is not parsed
+var ErrUnreferencedPage = errors.New("page not referenced by document.json")
+
+// pageEntries lists the page files of fsys in the order of the document
+// page references. The pages the references do not list are returned
+// apart, unless the document has no references at all: then every page
+// of fsys is listed, as with an exploded directory missing document.json
+func pageEntries(fsys fs.FS, doc Document) ([]string, []string, error) {
+	all, err := fs.Glob(fsys, "pages/*.json")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := map[string]bool{}
+	names := []string{}
+	for _, ref := range doc.Pages {
+		if ref == nil {
+			continue
+		}
+		name := ref.Ref + ".json"
+		if seen[name] {
+			continue
+		}
+		if _, err := fs.Stat(fsys, name); err != nil {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	unreferenced := []string{}
+	for _, name := range all {
+		if seen[name] {
+			continue
+		}
+		if len(doc.Pages) == 0 {
+			names = append(names, name)
+		} else {
+			unreferenced = append(unreferenced, name)
+		}
+	}
+	return names, unreferenced, nil
 }
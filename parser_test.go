@@ -0,0 +1,102 @@
+package sketch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+)
+
+// minimalFS is a document of one empty page
+func minimalFS() fstest.MapFS {
+	return fstest.MapFS{
+		"document.json": {Data: []byte(`{"_class":"document","do_objectID":"D1","pages":[{"_class":"MSJSONFileReference","_ref_class":"MSImmutablePage","_ref":"pages/P1"}]}`)},
+		"meta.json":     {Data: []byte(`{"version":146,"pagesAndArtboards":{"P1":{"name":"Page 1"}}}`)},
+		"pages/P1.json": {Data: []byte(`{"_class":"page","do_objectID":"P1","name":"Page 1","layers":[]}`)},
+	}
+}
+
+func TestParseContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ParseFSContext(ctx, minimalFS(), ParseOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseFSContext: got %v, want context.Canceled", err)
+	}
+
+	f, err := ParseFS(minimalFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := f.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	if _, err := ParseReaderContext(ctx, bytes.NewReader(b), int64(len(b)), ParseOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseReaderContext: got %v, want context.Canceled", err)
+	}
+}
+
+// The entry points all decode the same file, whether from a path,
+// from memory or from an already un-compressed file system
+func TestParseEntryPoints(t *testing.T) {
+	fsys := minimalFS()
+	want, err := ParseFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Document.DoObjectID != "D1" || len(want.Pages) != 1 || want.Pages[0].Name != "Page 1" {
+		t.Fatalf("ParseFS: got %+v", want)
+	}
+
+	b := zipOf(t, fsys)
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "doc.sketch")
+	if err := os.WriteFile(archive, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exploded := filepath.Join(dir, "exploded")
+	for name, f := range fsys {
+		name = filepath.Join(exploded, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, f.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, parse := range map[string]func() (*File, error){
+		"Parse":       func() (*File, error) { return Parse(archive) },
+		"Parse dir":   func() (*File, error) { return Parse(exploded) },
+		"ParseReader": func() (*File, error) { return ParseReader(bytes.NewReader(b), int64(len(b))) },
+		"ParseBytes":  func() (*File, error) { return ParseBytes(b) },
+	} {
+		got, err := parse()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestParseEntryPointErrors(t *testing.T) {
+	if _, err := ParseBytes([]byte("not a sketch file")); !errors.Is(err, zip.ErrFormat) {
+		t.Errorf("ParseBytes: got %v, want zip.ErrFormat", err)
+	}
+	if _, err := ParseFS(fstest.MapFS{"document.json": {Data: []byte(`[]`)}}); err == nil {
+		t.Error("ParseFS: no error for a document.json that is not an object")
+	}
+	if _, err := Parse(filepath.Join(t.TempDir(), "missing.sketch")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Parse: got %v, want os.ErrNotExist", err)
+	}
+}
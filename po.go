@@ -0,0 +1,150 @@
+package sketch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WritePO writes the catalog as a gettext PO file,
+// with the key of each string as its msgctxt
+func WritePO(w io.Writer, c *Catalog) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "msgid \"\"\nmsgstr %s\n", poQuote(
+		"Content-Type: text/plain; charset=UTF-8\n"+
+			"Language: "+c.Locale+"\n"+
+			"X-Source-Language: "+c.SourceLocale+"\n"))
+
+	for _, t := range c.Translations {
+		bw.WriteString("\n")
+		if t.Note != "" {
+			fmt.Fprintf(bw, "#. %s\n", strings.ReplaceAll(t.Note, "\n", " "))
+		}
+		fmt.Fprintf(bw, "msgctxt %s\n", poQuote(t.Key))
+		fmt.Fprintf(bw, "msgid %s\n", poQuote(t.Source))
+		fmt.Fprintf(bw, "msgstr %s\n", poQuote(t.Target))
+	}
+	return bw.Flush()
+}
+
+// poQuote quotes s, splitting it after each newline like gettext does
+func poQuote(s string) string {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		return poEscape(s)
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	quoted := []string{`""`}
+	for _, line := range lines {
+		if line != "" {
+			quoted = append(quoted, poEscape(line))
+		}
+	}
+	return strings.Join(quoted, "\n")
+}
+
+func poEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// ReadPO reads a catalog from a gettext PO file written by WritePO.
+// Entries marked fuzzy are read without their translation
+func ReadPO(r io.Reader) (*Catalog, error) {
+	c := &Catalog{}
+
+	var t *Translation
+	var field *string
+	fuzzy := false
+	flush := func() {
+		if t == nil {
+			return
+		}
+		if fuzzy {
+			t.Target = ""
+		}
+		if t.Key == "" && t.Source == "" {
+			c.readHeader(t.Target)
+		} else {
+			c.Translations = append(c.Translations, t)
+		}
+		t, field, fuzzy = nil, nil, false
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#,"):
+			if t != nil && t.Key+t.Source+t.Target != "" {
+				flush()
+			}
+			fuzzy = strings.Contains(line, "fuzzy")
+		case strings.HasPrefix(line, "#."):
+			if t == nil {
+				t = &Translation{}
+			}
+			t.Note = strings.TrimSpace(strings.TrimPrefix(line, "#."))
+		case strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return nil, errors.Errorf("po line %d: string without keyword", n)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, errors.Wrapf(err, "po line %d", n)
+			}
+			*field += s
+		default:
+			keyword, value, _ := strings.Cut(line, " ")
+			if t == nil {
+				t = &Translation{}
+			}
+			switch keyword {
+			case "msgctxt":
+				field = &t.Key
+			case "msgid":
+				field = &t.Source
+			case "msgstr":
+				field = &t.Target
+			default:
+				return nil, errors.Errorf("po line %d: unsupported keyword %q", n, keyword)
+			}
+			s, err := strconv.Unquote(strings.TrimSpace(value))
+			if err != nil {
+				return nil, errors.Wrapf(err, "po line %d", n)
+			}
+			*field = s
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return c, nil
+}
+
+// readHeader reads the locales from the header entry of a PO file
+func (c *Catalog) readHeader(header string) {
+	for _, line := range strings.Split(header, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "Language":
+			c.Locale = strings.TrimSpace(value)
+		case "X-Source-Language":
+			c.SourceLocale = strings.TrimSpace(value)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package sketch
+
+import (
+	"io"
+	"iter"
+)
+
+// Reader reads the pages of a sketch file one at a time, so only the
+// page being visited has to be held in memory
+type Reader struct {
+	p      *parser
+	closer io.Closer
+	file   *File
+	names  []string
+	// warned records the pages whose warnings are in file.Warnings
+	warned map[string]bool
+}
+
+// Open opens the sketch file at src, parsing everything but the pages
+func Open(src string) (*Reader, error) {
+	return OpenWithOptions(src, ParseOptions{})
+}
+
+// OpenWithOptions is Open, parsing as configured by opts.
+// Concurrency is ignored as pages are decoded when iterated
+func OpenWithOptions(src string, opts ParseOptions) (*Reader, error) {
+	fsys, closer, err := openFS(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newParser(fsys, opts)
+	file, names, err := p.header()
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+
+	return &Reader{
+		p:      p,
+		closer: closer,
+		file:   file,
+		names:  names,
+		warned: map[string]bool{},
+	}, nil
+}
+
+// Document returns the content of document.json
+func (r *Reader) Document() *Document {
+	return &r.file.Document
+}
+
+// Meta returns the content of meta.json
+func (r *Reader) Meta() *Meta {
+	return &r.file.Meta
+}
+
+// User returns the content of user.json
+func (r *Reader) User() *UserState {
+	return &r.file.User
+}
+
+// Warnings returns the problems lenient mode recovered from,
+// for the pages iterated so far
+func (r *Reader) Warnings() []*ParseError {
+	return r.file.Warnings
+}
+
+// Pages decodes the pages in document order, one for each iteration.
+// The iteration stops after the first page that fails to decode.
+// Pages may be iterated again, decoding each page again, and the
+// warnings of a page are only recorded the first time
+func (r *Reader) Pages() iter.Seq2[*Page, error] {
+	return func(yield func(*Page, error) bool) {
+		for _, name := range r.names {
+			e := r.p.entry(name)
+			page, err := e.page()
+			if !r.warned[name] {
+				r.file.Warnings = append(r.file.Warnings, e.warnings...)
+				r.warned[name] = true
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if page == nil {
+				continue
+			}
+			if !yield(page, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes the underlying sketch file
+func (r *Reader) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,86 @@
+package sketch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// openFile saves fsys as a sketch file and opens it with opts
+func openFile(t *testing.T, fsys fstest.MapFS, opts ParseOptions) *Reader {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "doc.sketch")
+	if err := os.WriteFile(src, zipOf(t, fsys), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenWithOptions(src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+// readerFS has the pages P1, holding a broken layer, and P2
+func readerFS() fstest.MapFS {
+	fsys := pageFS(`{"_class":"rectangle","do_objectID":"R1","frame":"broken"}`)
+	fsys["document.json"].Data = []byte(`{"_class":"document","do_objectID":"D1","pages":[{"_ref":"pages/P1"},{"_ref":"pages/P2"}]}`)
+	fsys["user.json"] = &fstest.MapFile{Data: []byte(`{"P1":{"zoomValue":2}}`)}
+	fsys["pages/P2.json"] = &fstest.MapFile{Data: []byte(`{"_class":"page","do_objectID":"P2","name":"Page 2","layers":[]}`)}
+	return fsys
+}
+
+// pageIDs iterates the pages of r, listing their IDs
+func pageIDs(t *testing.T, r *Reader) []string {
+	t.Helper()
+	ids := []string{}
+	for page, err := range r.Pages() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, page.DoObjectID)
+	}
+	return ids
+}
+
+func TestReaderPages(t *testing.T) {
+	r := openFile(t, readerFS(), ParseOptions{Mode: ParseLenient})
+	if r.Document().DoObjectID != "D1" || r.Meta().Version != "146" || r.User().Pages["P1"].ZoomValue != "2" {
+		t.Fatalf("got document %+v, meta %+v and user %+v", r.Document(), r.Meta(), r.User())
+	}
+	if len(r.Warnings()) != 0 {
+		t.Fatalf("got warnings %v before iterating", r.Warnings())
+	}
+
+	for i := 0; i < 2; i++ {
+		if ids := pageIDs(t, r); len(ids) != 2 || ids[0] != "P1" || ids[1] != "P2" {
+			t.Fatalf("iteration %d: got pages %v", i, ids)
+		}
+		if w := r.Warnings(); len(w) != 1 || w[0].ObjectID != "R1" {
+			t.Fatalf("iteration %d: got warnings %v, want the one of R1", i, w)
+		}
+	}
+
+	// stopping early leaves the remaining pages unread
+	for page := range r.Pages() {
+		if page.DoObjectID != "P1" {
+			t.Fatalf("got page %s first", page.DoObjectID)
+		}
+		break
+	}
+}
+
+func TestReaderPageError(t *testing.T) {
+	r := openFile(t, readerFS(), ParseOptions{})
+	n := 0
+	for page, err := range r.Pages() {
+		n++
+		if page != nil || err == nil {
+			t.Fatalf("got page %v and error %v, want the error decoding P1", page, err)
+		}
+	}
+	if n != 1 {
+		t.Fatalf("iterated %d times after the error", n)
+	}
+}
@@ -0,0 +1,190 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// The scanners below split JSON objects and arrays into their raw
+// values without decoding them, so a property decoded by a custom
+// UnmarshalJSON is handed its bytes without another pass of
+// encoding/json. They expect valid JSON, like UnmarshalJSON is given,
+// and report false on anything else so callers fall back to json.Unmarshal
+
+// scanObject calls fn with the key and the raw value of each property
+// of the object data, until fn returns false. It returns false when
+// data is not an object
+func scanObject(data []byte, fn func(key string, value []byte) bool) bool {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return false
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return true
+	}
+
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return false
+		}
+		end, ok := skipString(data, i)
+		if !ok {
+			return false
+		}
+		key, ok := jsonString(data[i:end])
+		if !ok {
+			return false
+		}
+
+		i = skipSpace(data, end)
+		if i >= len(data) || data[i] != ':' {
+			return false
+		}
+		i = skipSpace(data, i+1)
+		end, ok = skipValue(data, i)
+		if !ok {
+			return false
+		}
+		if !fn(key, data[i:end]) {
+			return true
+		}
+
+		i = skipSpace(data, end)
+		if i >= len(data) {
+			return false
+		}
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case '}':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// scanArray calls fn with the raw value of each element of the array
+// data, until fn returns false. It returns false when data is not an array
+func scanArray(data []byte, fn func(value []byte) bool) bool {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '[' {
+		return false
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == ']' {
+		return true
+	}
+
+	for {
+		end, ok := skipValue(data, i)
+		if !ok {
+			return false
+		}
+		if !fn(data[i:end]) {
+			return true
+		}
+
+		i = skipSpace(data, end)
+		if i >= len(data) {
+			return false
+		}
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case ']':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipString returns the end of the string starting at data[i]
+func skipString(data []byte, i int) (int, bool) {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// skipValue returns the end of the value starting at data[i]
+func skipValue(data []byte, i int) (int, bool) {
+	if i >= len(data) {
+		return 0, false
+	}
+
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+	case '{', '[':
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				end, ok := skipString(data, i)
+				if !ok {
+					return 0, false
+				}
+				i = end
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1, true
+				}
+			}
+			i++
+		}
+		return 0, false
+	case '}', ']', ',', ':':
+		return 0, false
+	}
+
+	// a number, true, false or null
+	end := i
+	for end < len(data) {
+		switch data[end] {
+		case ',', '}', ']', ' ', '\t', '\r', '\n':
+			return end, true
+		}
+		end++
+	}
+	return end, true
+}
+
+// jsonString decodes the raw JSON string s
+func jsonString(s []byte) (string, bool) {
+	if len(s) < 2 || s[0] != '"' {
+		return "", false
+	}
+	if bytes.IndexByte(s, '\\') < 0 {
+		return string(s[1 : len(s)-1]), true
+	}
+
+	str := ""
+	if err := json.Unmarshal(s, &str); err != nil {
+		return "", false
+	}
+	return str, true
+}
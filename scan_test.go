@@ -0,0 +1,52 @@
+package sketch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanObject(t *testing.T) {
+	tests := []struct {
+		data string
+		keys []string
+		ok   bool
+	}{
+		{`{}`, nil, true},
+		{` { "a" : 1 , "b\"c" : "}" , "d" : [ {"e": "]"} ] , "f": null } `, []string{"a", `b"c`, "d", "f"}, true},
+		{`{"a":true}`, []string{"a"}, true},
+		{`[]`, nil, false},
+		{`{"a"}`, nil, false},
+		{`{"a":1`, []string{"a"}, false},
+		{`{"a":{"b":1}`, nil, false},
+		{`{1}`, nil, false},
+		{``, nil, false},
+	}
+	for _, tt := range tests {
+		keys := []string(nil)
+		ok := scanObject([]byte(tt.data), func(key string, value []byte) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if ok != tt.ok || (ok && !reflect.DeepEqual(keys, tt.keys)) {
+			t.Errorf("scanObject(%q) = %v %q, want %v %q", tt.data, ok, keys, tt.ok, tt.keys)
+		}
+	}
+}
+
+func TestScanArray(t *testing.T) {
+	values := []string{}
+	ok := scanArray([]byte(` [ 1 , "a,b" , {"c":[2]} , null ] `), func(value []byte) bool {
+		values = append(values, string(value))
+		return true
+	})
+	want := []string{`1`, `"a,b"`, `{"c":[2]}`, `null`}
+	if !ok || !reflect.DeepEqual(values, want) {
+		t.Fatalf("got %v %q, want %q", ok, values, want)
+	}
+
+	for _, data := range []string{`{}`, `[1,]`, `[1 2]`, `[`} {
+		if scanArray([]byte(data), func([]byte) bool { return true }) {
+			t.Errorf("scanArray(%q) succeeded", data)
+		}
+	}
+}
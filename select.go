@@ -0,0 +1,137 @@
+package sketch
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// matches reports whether any of patterns is the do_objectID id,
+// or a path.Match pattern matching name
+func matches(patterns []string, id, name string) bool {
+	for _, pattern := range patterns {
+		if pattern == id {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func checkPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// selectPages filters the page entries by the Pages and Artboards
+// options, using the index of meta.json. Pages missing from the index
+// are kept, to be filtered once their content is read
+func (p *parser) selectPages(meta *Meta, names []string) ([]string, error) {
+	if len(p.opts.Pages) == 0 && len(p.opts.Artboards) == 0 {
+		return names, nil
+	}
+	if err := checkPatterns(p.opts.Pages); err != nil {
+		return nil, err
+	}
+	if err := checkPatterns(p.opts.Artboards); err != nil {
+		return nil, err
+	}
+
+	selected := []string{}
+	for _, name := range names {
+		id := strings.TrimSuffix(path.Base(name), ".json")
+		pm := meta.PagesAndArtboards[id]
+		if pm == nil {
+			selected = append(selected, name)
+			continue
+		}
+		if len(p.opts.Pages) > 0 && !matches(p.opts.Pages, id, pm.Name) {
+			continue
+		}
+		if len(p.opts.Artboards) > 0 && !p.anyArtboard(pm) {
+			continue
+		}
+		selected = append(selected, name)
+	}
+	return selected, nil
+}
+
+func (p *parser) anyArtboard(pm *PageMeta) bool {
+	for id, am := range pm.Artboards {
+		if am != nil && matches(p.opts.Artboards, id, am.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// layerHead is the part of a layer needed to select it
+type layerHead struct {
+	Class      string `json:"_class"`
+	DoObjectID string `json:"do_objectID"`
+	Name       string `json:"name"`
+}
+
+// selectLayers replaces the top level layers of the page JSON that
+// are not selected by null, so they are never decoded. It reports
+// false when the page itself is not selected
+func (p *parser) selectLayers(data []byte) ([]byte, bool, error) {
+	if len(p.opts.Pages) == 0 && len(p.opts.Artboards) == 0 {
+		return data, true, nil
+	}
+
+	page := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &page); err != nil {
+		// leave reporting the error to the decoding of the page
+		return data, true, nil
+	}
+
+	head := layerHead{}
+	json.Unmarshal(data, &head)
+	if len(p.opts.Pages) > 0 && !matches(p.opts.Pages, head.DoObjectID, head.Name) {
+		return nil, false, nil
+	}
+	if len(p.opts.Artboards) == 0 {
+		return data, true, nil
+	}
+
+	layers := []json.RawMessage{}
+	if err := json.Unmarshal(page["layers"], &layers); err != nil {
+		return data, true, nil
+	}
+
+	selected := false
+	for i, raw := range layers {
+		head := layerHead{}
+		json.Unmarshal(raw, &head)
+		if (head.Class == "artboard" || head.Class == "symbolMaster") &&
+			matches(p.opts.Artboards, head.DoObjectID, head.Name) {
+			selected = true
+			continue
+		}
+		layers[i] = json.RawMessage("null")
+	}
+	if !selected {
+		return nil, false, nil
+	}
+
+	b, err := json.Marshal(layers)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "select layers")
+	}
+	page["layers"] = b
+
+	data, err = json.Marshal(page)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "select layers")
+	}
+	return data, true, nil
+}
@@ -0,0 +1,75 @@
+package sketch
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// selectFS has the pages Home and Components in the meta.json index,
+// and the page Archive missing from it
+func selectFS() fstest.MapFS {
+	artboard := func(id, name string) string {
+		return `{"_class":"artboard","do_objectID":"` + id + `","name":"` + name + `","frame":{},"layers":[]}`
+	}
+	page := func(id, name string, layers ...string) *fstest.MapFile {
+		data := `{"_class":"page","do_objectID":"` + id + `","name":"` + name + `","layers":[` + strings.Join(layers, ",") + `]}`
+		return &fstest.MapFile{Data: []byte(data)}
+	}
+
+	return fstest.MapFS{
+		"document.json": {Data: []byte(`{"_class":"document","do_objectID":"D1","pages":[{"_ref":"pages/P1"},{"_ref":"pages/P2"},{"_ref":"pages/P3"}]}`)},
+		"meta.json": {Data: []byte(`{"version":146,"pagesAndArtboards":{` +
+			`"P1":{"name":"Home","artboards":{"A1":{"name":"Login"},"A2":{"name":"Signup"}}},` +
+			`"P2":{"name":"Components","artboards":{"B1":{"name":"Button"}}}}}`)},
+		"pages/P1.json": page("P1", "Home", artboard("A1", "Login"), artboard("A2", "Signup"), `{"_class":"group","do_objectID":"G1","name":"Login","frame":{},"layers":[]}`),
+		"pages/P2.json": page("P2", "Components", artboard("B1", "Button")),
+		"pages/P3.json": page("P3", "Archive", artboard("C1", "Login old")),
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		pages, artboards []string
+		// want lists the layers of each page parsed, by page
+		want map[string][]string
+	}{
+		{nil, nil, map[string][]string{"P1": {"A1", "A2", "G1"}, "P2": {"B1"}, "P3": {"C1"}}},
+		{[]string{"P2"}, nil, map[string][]string{"P2": {"B1"}}},
+		{[]string{"H*"}, nil, map[string][]string{"P1": {"A1", "A2", "G1"}}},
+		// pages missing from the index are matched once read
+		{[]string{"Arch*"}, nil, map[string][]string{"P3": {"C1"}}},
+		{[]string{"Nothing"}, nil, map[string][]string{}},
+		// only matching artboards are kept, pages without one are dropped
+		{nil, []string{"Login*"}, map[string][]string{"P1": {"A1"}, "P3": {"C1"}}},
+		{nil, []string{"B1"}, map[string][]string{"P2": {"B1"}}},
+		{[]string{"Home"}, []string{"Sign*", "B1"}, map[string][]string{"P1": {"A2"}}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFSContext(context.Background(), selectFS(), ParseOptions{Pages: tt.pages, Artboards: tt.artboards})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string][]string{}
+		for _, page := range f.Pages {
+			ids := []string{}
+			for _, layer := range page.Layers {
+				ids = append(ids, layer.Base().DoObjectID)
+			}
+			got[page.DoObjectID] = ids
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pages %q artboards %q: got %v, want %v", tt.pages, tt.artboards, got, tt.want)
+		}
+	}
+}
+
+func TestSelectInvalidPattern(t *testing.T) {
+	for _, opts := range []ParseOptions{{Pages: []string{"["}}, {Artboards: []string{"Home", "a[b"}}} {
+		if _, err := ParseFSContext(context.Background(), selectFS(), opts); err == nil {
+			t.Errorf("%+v: parsed with an invalid pattern", opts)
+		}
+	}
+}
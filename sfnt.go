@@ -0,0 +1,124 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// sfntPostScriptNames returns the PostScript names of the fonts of a
+// TrueType or OpenType font file, or of each font of a collection
+func sfntPostScriptNames(r io.ReaderAt) ([]string, error) {
+	tag := make([]byte, 12)
+	if _, err := r.ReadAt(tag, 0); err != nil {
+		return nil, errors.Wrap(err, "sfnt header")
+	}
+
+	if string(tag[:4]) != "ttcf" {
+		name, err := sfntPostScriptName(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		return []string{name}, nil
+	}
+
+	count := binary.BigEndian.Uint32(tag[8:])
+	if count > 1<<16 {
+		return nil, errors.Errorf("font collection of %d fonts", count)
+	}
+	offsets := make([]byte, 4*count)
+	if _, err := r.ReadAt(offsets, 12); err != nil {
+		return nil, errors.Wrap(err, "font collection")
+	}
+
+	names := []string{}
+	for i := uint32(0); i < count; i++ {
+		name, err := sfntPostScriptName(r, int64(binary.BigEndian.Uint32(offsets[4*i:])))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// sfntPostScriptName reads name ID 6 of the name table
+// of the font whose offset table is at offset
+func sfntPostScriptName(r io.ReaderAt, offset int64) (string, error) {
+	head := make([]byte, 12)
+	if _, err := r.ReadAt(head, offset); err != nil {
+		return "", errors.Wrap(err, "sfnt offset table")
+	}
+	switch string(head[:4]) {
+	case "\x00\x01\x00\x00", "OTTO", "true":
+	default:
+		return "", errors.Errorf("not an sfnt font: %q", head[:4])
+	}
+
+	numTables := int(binary.BigEndian.Uint16(head[4:]))
+	records := make([]byte, 16*numTables)
+	if _, err := r.ReadAt(records, offset+12); err != nil {
+		return "", errors.Wrap(err, "sfnt table records")
+	}
+
+	for i := 0; i < numTables; i++ {
+		rec := records[16*i:]
+		if string(rec[:4]) != "name" {
+			continue
+		}
+		tableOffset := int64(binary.BigEndian.Uint32(rec[8:]))
+		length := binary.BigEndian.Uint32(rec[12:])
+		if length > 1<<20 {
+			return "", errors.Errorf("name table of %d bytes", length)
+		}
+		table := make([]byte, length)
+		if _, err := r.ReadAt(table, tableOffset); err != nil {
+			return "", errors.Wrap(err, "name table")
+		}
+		return nameTablePostScriptName(table)
+	}
+	return "", errors.New("font has no name table")
+}
+
+func nameTablePostScriptName(table []byte) (string, error) {
+	if len(table) < 6 {
+		return "", errors.New("short name table")
+	}
+	count := int(binary.BigEndian.Uint16(table[2:]))
+	storage := int(binary.BigEndian.Uint16(table[4:]))
+	if len(table) < 6+12*count {
+		return "", errors.New("short name table")
+	}
+
+	mac := ""
+	for i := 0; i < count; i++ {
+		rec := table[6+12*i:]
+		platform := binary.BigEndian.Uint16(rec)
+		nameID := binary.BigEndian.Uint16(rec[6:])
+		length := int(binary.BigEndian.Uint16(rec[8:]))
+		start := storage + int(binary.BigEndian.Uint16(rec[10:]))
+		if nameID != 6 || start+length > len(table) {
+			continue
+		}
+
+		s := table[start : start+length]
+		switch platform {
+		case 0, 3:
+			// Unicode and Windows names are UTF-16BE
+			units := make([]uint16, len(s)/2)
+			for j := range units {
+				units[j] = binary.BigEndian.Uint16(s[2*j:])
+			}
+			return string(utf16.Decode(units)), nil
+		case 1:
+			mac = string(s)
+		}
+	}
+
+	if mac == "" {
+		return "", errors.New("font has no PostScript name")
+	}
+	return mac, nil
+}
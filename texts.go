@@ -0,0 +1,170 @@
+package sketch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// TextEntry is a string of the document, the text of a text layer
+// or a text override of a symbol instance
+type TextEntry struct {
+	PageID     string `json:"pageID"`
+	Page       string `json:"page"`
+	ArtboardID string `json:"artboardID,omitempty"`
+	Artboard   string `json:"artboard,omitempty"`
+	// Path is the names of the layers from the page to the text layer,
+	// through the symbol master for overrides
+	Path []string `json:"path"`
+	// ObjectID is the do_objectID of the text layer,
+	// or of the symbol instance for overrides
+	ObjectID string `json:"objectID"`
+	// OverrideName is the name of the override value for overrides,
+	// like `<id>_stringValue`
+	OverrideName string `json:"overrideName,omitempty"`
+	Text         string `json:"text"`
+}
+
+// Texts lists the strings of every page in document order,
+// each text layer followed by the text overrides of symbol instances
+func (f *File) Texts() []*TextEntry {
+	layers := f.symbolLayers()
+
+	texts := []*TextEntry{}
+	for _, page := range f.Pages {
+		if page == nil {
+			continue
+		}
+		w := textWalker{page: page, layers: layers}
+		w.walk(page.Layers, nil, nil)
+		texts = append(texts, w.texts...)
+	}
+	return texts
+}
+
+// symbolLayers indexes the layers of every symbol master by do_objectID,
+// to name the layers symbol instances override
+func (f *File) symbolLayers() map[string]Layer {
+	layers := map[string]Layer{}
+	add := func(l Layers) {
+		walkLayers(l, func(layer Layer) error {
+			if id := layer.Base().DoObjectID; id != "" {
+				layers[id] = layer
+			}
+			return nil
+		})
+	}
+
+	for _, page := range f.Pages {
+		if page == nil {
+			continue
+		}
+		for _, layer := range page.Layers {
+			if master, ok := layer.(*SymbolMaster); ok {
+				add(master.Layers)
+			}
+		}
+	}
+	if f.Document.LayerSymbols != nil {
+		add(f.Document.LayerSymbols.Objects)
+	}
+	for _, fs := range f.Document.ForeignSymbols {
+		if fs != nil && fs.SymbolMaster != nil {
+			add(fs.SymbolMaster.Layers)
+		}
+	}
+	return layers
+}
+
+type textWalker struct {
+	page   *Page
+	layers map[string]Layer
+	texts  []*TextEntry
+}
+
+func (w *textWalker) walk(layers Layers, path []string, artboard Layer) {
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		base := layer.Base()
+		layerPath := append(append([]string{}, path...), base.Name)
+
+		ab := artboard
+		switch l := layer.(type) {
+		case *Artboard, *SymbolMaster:
+			if ab == nil {
+				ab = layer
+			}
+		case *Text:
+			if l.AttributedString != nil {
+				w.add(ab, layerPath, base.DoObjectID, "", l.AttributedString.String)
+			}
+		case *SymbolInstance:
+			for _, ov := range l.OverrideValues {
+				if ov == nil || !strings.HasSuffix(ov.OverrideName, "_stringValue") {
+					continue
+				}
+				text, ok := ov.Value.(string)
+				if !ok {
+					continue
+				}
+				w.add(ab, w.overridePath(layerPath, ov.OverrideName), base.DoObjectID, ov.OverrideName, text)
+			}
+		}
+
+		if c, ok := layer.(LayerContainer); ok {
+			w.walk(c.Children(), layerPath, ab)
+		}
+	}
+}
+
+// overridePath appends the names of the layers an override name goes through
+func (w *textWalker) overridePath(path []string, name string) []string {
+	name = strings.TrimSuffix(name, "_stringValue")
+	for _, id := range strings.Split(name, "/") {
+		if layer, ok := w.layers[id]; ok {
+			path = append(path, layer.Base().Name)
+		} else {
+			path = append(path, id)
+		}
+	}
+	return path
+}
+
+func (w *textWalker) add(artboard Layer, path []string, id, override, text string) {
+	entry := &TextEntry{
+		PageID:       w.page.DoObjectID,
+		Page:         w.page.Name,
+		Path:         path,
+		ObjectID:     id,
+		OverrideName: override,
+		Text:         text,
+	}
+	if artboard != nil {
+		entry.ArtboardID = artboard.Base().DoObjectID
+		entry.Artboard = artboard.Base().Name
+	}
+	w.texts = append(w.texts, entry)
+}
+
+// WriteTextsCSV writes texts as CSV with a header row,
+// the layer path is joined by `/`
+func WriteTextsCSV(w io.Writer, texts []*TextEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"page_id", "page", "artboard_id", "artboard", "path", "object_id", "override_name", "text"})
+	for _, t := range texts {
+		cw.Write([]string{t.PageID, t.Page, t.ArtboardID, t.Artboard, strings.Join(t.Path, "/"), t.ObjectID, t.OverrideName, t.Text})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTextsJSON writes texts as a JSON array
+func WriteTextsJSON(w io.Writer, texts []*TextEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(texts)
+}
@@ -0,0 +1,122 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func textLayerNamed(id, name, text string) *Text {
+	return &Text{
+		LayerBase:        LayerBase{DoObjectID: id, Name: name},
+		AttributedString: &MSAttributedString{String: text},
+	}
+}
+
+// textsFile has a text on an artboard, one in a group directly on the
+// page, and an override of a text of a symbol master on another page
+func textsFile() *File {
+	return &File{Pages: []*Page{
+		nil,
+		{DoObjectID: "P1", Name: "Home", Layers: Layers{
+			&Artboard{GroupBase: GroupBase{LayerBase: LayerBase{DoObjectID: "A1", Name: "Login"}, Layers: Layers{
+				textLayerNamed("T1", "Title", "Welcome"),
+				&SymbolInstance{
+					LayerBase: LayerBase{DoObjectID: "I1", Name: "Button"},
+					OverrideValues: []*OverrideValue{
+						{OverrideName: "M1T_stringValue", Value: "Sign in"},
+						{OverrideName: "M1I_image", Value: map[string]interface{}{}},
+					},
+				},
+			}}},
+			&Group{GroupBase: GroupBase{LayerBase: LayerBase{DoObjectID: "G1", Name: "Notes"}, Layers: Layers{
+				nil,
+				textLayerNamed("T2", "Note", "Draft, \"do not ship\""),
+			}}},
+		}},
+		nil,
+		{DoObjectID: "P2", Name: "Symbols", Layers: Layers{
+			&SymbolMaster{GroupBase: GroupBase{LayerBase: LayerBase{DoObjectID: "M1", Name: "Button"}, Layers: Layers{
+				textLayerNamed("M1T", "Label", "Button"),
+			}}},
+		}},
+	}}
+}
+
+func TestTexts(t *testing.T) {
+	f := textsFile()
+	want := []*TextEntry{
+		{PageID: "P1", Page: "Home", ArtboardID: "A1", Artboard: "Login", Path: []string{"Login", "Title"}, ObjectID: "T1", Text: "Welcome"},
+		{PageID: "P1", Page: "Home", ArtboardID: "A1", Artboard: "Login", Path: []string{"Login", "Button", "Label"}, ObjectID: "I1", OverrideName: "M1T_stringValue", Text: "Sign in"},
+		{PageID: "P1", Page: "Home", Path: []string{"Notes", "Note"}, ObjectID: "T2", Text: `Draft, "do not ship"`},
+		{PageID: "P2", Page: "Symbols", ArtboardID: "M1", Artboard: "Button", Path: []string{"Button", "Label"}, ObjectID: "M1T", Text: "Button"},
+	}
+	got := f.Texts()
+	if !reflect.DeepEqual(got, want) {
+		for _, e := range got {
+			t.Logf("%+v", e)
+		}
+		t.Fatal("unexpected texts")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteTextsCSV(buf, got); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := `page_id,page,artboard_id,artboard,path,object_id,override_name,text
+P1,Home,A1,Login,Login/Title,T1,,Welcome
+P1,Home,A1,Login,Login/Button/Label,I1,M1T_stringValue,Sign in
+P1,Home,,,Notes/Note,T2,,"Draft, ""do not ship"""
+P2,Symbols,M1,Button,Button/Label,M1T,,Button
+`
+	if buf.String() != wantCSV {
+		t.Fatalf("got CSV\n%s\nwant\n%s", buf, wantCSV)
+	}
+
+	buf.Reset()
+	if err := WriteTextsJSON(buf, got); err != nil {
+		t.Fatal(err)
+	}
+	decoded := []*TextEntry{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatalf("got JSON\n%s", buf)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"artboardID": ""`)) || !bytes.Contains(buf.Bytes(), []byte(`"overrideName": "M1T_stringValue"`)) {
+		t.Fatalf("got JSON\n%s", buf)
+	}
+}
+
+// Files may hold nil pages, which WriteTo skips
+func TestNilPages(t *testing.T) {
+	f := textsFile()
+	if p := f.Page("P2"); p == nil || p.Name != "Symbols" {
+		t.Fatalf("Page(P2) = %v", p)
+	}
+	if p := f.PageByName("Home"); p == nil || p.DoObjectID != "P1" {
+		t.Fatalf("PageByName(Home) = %v", p)
+	}
+	if p := f.Page("P3"); p != nil {
+		t.Fatalf("Page(P3) = %v", p)
+	}
+	if fonts := f.Fonts(); len(fonts) != 0 {
+		t.Fatalf("got fonts %v", fonts)
+	}
+
+	out, _, err := f.Localize(&Catalog{Translations: []*Translation{{Key: "T1", Target: "Bienvenue"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Pages) != 4 || out.Pages[0] != nil || out.Pages[2] != nil {
+		t.Fatalf("got pages %v, want the nil pages kept", out.Pages)
+	}
+	if texts := out.Texts(); texts[0].Text != "Bienvenue" {
+		t.Fatalf("got %q, want the translation", texts[0].Text)
+	}
+	if _, err := out.WriteTo(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,11 +1,12 @@
 package sketch
 
 import (
-	"bytes"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 	"howett.net/plist"
@@ -18,11 +19,13 @@ type Document struct {
 	CurrentPageIndex       json.Number               `json:"currentPageIndex"`
 	EnableLayerInteraction bool                      `json:"enableLayerInteraction"`
 	EnableSliceInteraction bool                      `json:"enableSliceInteraction"`
-	ForeignSymbols         []interface{}             `json:"foreignSymbols"`
+	ColorSpace             json.Number               `json:"colorSpace,omitempty"`
+	ForeignSymbols         []*ForeignSymbol          `json:"foreignSymbols"`
 	LayerStyles            *SharedStyleContainer     `json:"layerStyles"`
 	LayerSymbols           *SharedSymbolContainer    `json:"layerSymbols"`
 	LayerTextStyles        *SharedTextStyleContainer `json:"layerTextStyles"`
 	Pages                  []*MSJSONFileReference    `json:"pages"`
+	Extra                  Extra                     `json:"-"`
 }
 
 type Page struct {
@@ -38,7 +41,7 @@ type Page struct {
 	IsLocked              bool           `json:"isLocked"`
 	IsVisible             bool           `json:"isVisible"`
 	LayerListExpandedType json.Number    `json:"layerListExpandedType"`
-	Layers                []*Layer       `json:"layers"`
+	Layers                Layers         `json:"layers"`
 	Name                  string         `json:"name"`
 	NameIsFixed           bool           `json:"nameIsFixed"`
 	ResizingType          int64          `json:"resizingType"`
@@ -46,81 +49,135 @@ type Page struct {
 	ShouldBreakMaskChain  bool           `json:"shouldBreakMaskChain"`
 	Style                 *Style         `json:"style"`
 	VerticalRulerData     *RulerData     `json:"verticalRulerData"`
+	Extra                 Extra          `json:"-"`
 }
 
-type Layer struct {
-	DoObjectID                        string                     `json:"do_objectID"`
-	Name                              string                     `json:"name"`
-	Class                             string                     `json:"_class"`
-	Frame                             Rect                       `json:"frame"`
-	IsFlippedHorizontal               bool                       `json:"isFlippedHorizontal"`
-	IsFlippedVertical                 bool                       `json:"isFlippedVertical"`
-	IsLocked                          bool                       `json:"isLocked"`
-	IsVisible                         bool                       `json:"isVisible"`
-	ResizesContent                    bool                       `json:"resizesContent"`
-	ResizingConstraint                json.Number                `json:"resizingConstraint"`
-	ResizingType                      int64                      `json:"resizingType"`
-	Radius                            json.Number                `json:"radius"`
-	Rotation                          json.Number                `json:"rotation"`
-	ShouldBreakMaskChain              bool                       `json:"shouldBreakMaskChain"`
-	Style                             *Style                     `json:"style"`
-	Layers                            []*Layer                   `json:"layers"`
-	AttributedString                  *MSAttributedString        `json:"attributedString"`
-	AutomaticallyDrawOnUnderlyingPath bool                       `json:"automaticallyDrawOnUnderlyingPath"`
-	BackgroundColor                   *Color                     `json:"backgroundColor"`
-	BooleanOperation                  int64                      `json:"booleanOperation"`
-	ClippingMask                      *NestedPositionCoordinates `json:"clippingMask"`
-	ClippingMaskMode                  json.Number                `json:"clippingMaskMode"`
-	DontSynchroniseWithSymbol         bool                       `json:"dontSynchroniseWithSymbol"`
-	Edited                            bool                       `json:"edited"`
-	ExportOptions                     *ExportOptions             `json:"exportOptions"`
-	FillReplacesImage                 bool                       `json:"fillReplacesImage"`
-	FixedRadius                       json.Number                `json:"fixedRadius"`
-	GlyphBounds                       *NestedPositionCoordinates `json:"glyphBounds"`
-	HasBackgroundColor                bool                       `json:"hasBackgroundColor"`
-	HasClickThrough                   bool                       `json:"hasClickThrough"`
-	HasClippingMask                   bool                       `json:"hasClippingMask"`
-	HasConvertedToNewRoundCorners     bool                       `json:"hasConvertedToNewRoundCorners"`
-	HeightIsClipped                   bool                       `json:"heightIsClipped"`
-	HorizontalRulerData               *RulerData                 `json:"horizontalRulerData"`
-	HorizontalSpacing                 json.Number                `json:"horizontalSpacing"`
-	Image                             *MSJSONFileReference       `json:"image"`
-	IncludeBackgroundColorInExport    bool                       `json:"includeBackgroundColorInExport"`
-	IncludeBackgroundColorInInstance  bool                       `json:"includeBackgroundColorInInstance"`
-	IncludeInCloudUpload              bool                       `json:"includeInCloudUpload"`
-	IsEquilateral                     bool                       `json:"isEquilateral"`
-	Layout                            *LayoutGrid                `json:"layout"`
-	LayerListExpandedType             json.Number                `json:"layerListExpandedType"`
-	LineSpacingBehaviour              json.Number                `json:"lineSpacingBehaviour"`
-	MasterInfluenceEdgeMaxXPadding    json.Number                `json:"masterInfluenceEdgeMaxXPadding"`
-	MasterInfluenceEdgeMaxYPadding    json.Number                `json:"masterInfluenceEdgeMaxYPadding"`
-	MasterInfluenceEdgeMinXPadding    json.Number                `json:"masterInfluenceEdgeMinXPadding"`
-	MasterInfluenceEdgeMinYPadding    json.Number                `json:"masterInfluenceEdgeMinYPadding"`
-	NumberOfPoints                    int64                      `json:"numberOfPoints"`
-	NameIsFixed                       bool                       `json:"nameIsFixed"`
-	NineSliceCenterRect               *NestedPositionCoordinates `json:"nineSliceCenterRect"`
-	NineSliceScale                    *PositionCoordinates       `json:"nineSliceScale"`
-	OriginalObjectID                  string                     `json:"originalObjectID"`
-	Overrides                         *Overrides                 `json:"overrides"`
-	Path                              *Path                      `json:"path"`
-	SymbolID                          string                     `json:"symbolID"`
-	TextBehaviour                     json.Number                `json:"textBehaviour,omitempty"`
-	VerticalRulerData                 *RulerData                 `json:"verticalRulerData,omitempty"`
-	VerticalSpacing                   json.Number                `json:"verticalSpacing"`
-	WindingRule                       json.Number                `json:"windingRule"`
+type Meta struct {
+	Commit               string               `json:"commit"`
+	AppVersion           string               `json:"appVersion"`
+	Build                json.Number          `json:"build"`
+	App                  string               `json:"app"`
+	CompatibilityVersion json.Number          `json:"compatibilityVersion"`
+	PagesAndArtboards    map[string]*PageMeta `json:"pagesAndArtboards"`
+	Fonts                []string             `json:"fonts"`
+	Version              json.Number          `json:"version"`
+	SaveHistory          []*SaveHistoryEntry  `json:"saveHistory"`
+	Autosaved            json.Number          `json:"autosaved"`
+	Variant              string               `json:"variant"`
+	Created              *MetaCreated         `json:"created"`
+	Extra                Extra                `json:"-"`
+}
+
+// MetaCreated describes the Sketch build that first created the document
+type MetaCreated struct {
+	Commit               string      `json:"commit"`
+	AppVersion           string      `json:"appVersion"`
+	Build                json.Number `json:"build"`
+	App                  string      `json:"app"`
+	CompatibilityVersion json.Number `json:"compatibilityVersion"`
+	Version              json.Number `json:"version"`
+	Variant              string      `json:"variant"`
+	Extra                Extra       `json:"-"`
+}
+
+// PageMeta is the meta.json index entry of a page, keyed by the page do_objectID
+type PageMeta struct {
+	Name      string                   `json:"name"`
+	Artboards map[string]*ArtboardMeta `json:"artboards"`
+	Extra     Extra                    `json:"-"`
+}
+
+// ArtboardMeta is the meta.json index entry of an artboard, keyed by the artboard do_objectID
+type ArtboardMeta struct {
+	Name  string `json:"name"`
+	Extra Extra  `json:"-"`
+}
+
+// SaveHistoryEntry is one save of the document, stored as "<variant>.<build>"
+// Example `NONAPPSTORE.38999`
+type SaveHistoryEntry struct {
+	Variant string
+	Build   json.Number
+}
+
+func (e *SaveHistoryEntry) MarshalJSON() ([]byte, error) {
+	if e.Variant == "" {
+		return json.Marshal(e.Build.String())
+	}
+	return json.Marshal(e.Variant + "." + e.Build.String())
 }
 
-type Meta struct {
-	Commit           string            `json:"commit"`
-	AppVersion       string            `json:"appVersion"`
-	Build            json.Number       `json:"build"`
-	App              string            `json:"app"`
-	PagesAndArtboard map[string]string `json:"pagesAndArtboards"`
-	Fonts            []string          `json:"fonts"`
-	Version          json.Number       `json:"version"`
-	SaveHistory      []string          `json:"saveHistory"`
-	Autosaved        json.Number       `json:"autosaved"`
-	Variant          string            `json:"variant"`
+func (e *SaveHistoryEntry) UnmarshalJSON(b []byte) error {
+	s := ""
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	i := strings.LastIndex(s, ".")
+	e.Variant = s[:max(i, 0)]
+	e.Build = json.Number(s[i+1:])
+
+	return nil
+}
+
+// UserState is the content of user.json, the viewport and UI state
+// Sketch restores when the document is opened
+type UserState struct {
+	Document *DocumentUserState
+	Pages    map[string]*PageUserState
+}
+
+type DocumentUserState struct {
+	PageListHeight                  json.Number `json:"pageListHeight"`
+	PageListCollapsed               json.Number `json:"pageListCollapsed"`
+	ExpandedSymbolPathsInSidebar    []string    `json:"expandedSymbolPathsInSidebar,omitempty"`
+	ExpandedTextStylePathsInPopover []string    `json:"expandedTextStylePathsInPopover,omitempty"`
+	Extra                           Extra       `json:"-"`
+}
+
+type PageUserState struct {
+	ScrollOrigin *PositionCoordinates `json:"scrollOrigin"`
+	ZoomValue    json.Number          `json:"zoomValue"`
+	Extra        Extra                `json:"-"`
+}
+
+func (u *UserState) MarshalJSON() ([]byte, error) {
+	out := map[string]interface{}{}
+	for id, p := range u.Pages {
+		out[id] = p
+	}
+	if u.Document != nil {
+		out["document"] = u.Document
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON splits user.json into the document state and
+// the per page state keyed by the page do_objectID
+func (u *UserState) UnmarshalJSON(b []byte) error {
+	data := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	u.Pages = map[string]*PageUserState{}
+	for key, raw := range data {
+		if key == "document" {
+			u.Document = &DocumentUserState{}
+			if err := json.Unmarshal(raw, u.Document); err != nil {
+				return err
+			}
+			continue
+		}
+
+		p := &PageUserState{}
+		if err := json.Unmarshal(raw, p); err != nil {
+			return err
+		}
+		u.Pages[key] = p
+	}
+
+	return nil
 }
 
 type LayoutGrid struct {
@@ -138,6 +195,7 @@ type LayoutGrid struct {
 	NumberOfColumns         int64       `json:"numberOfColumns"`
 	RowHeightMultiplication json.Number `json:"rowHeightMultiplication"`
 	TotalWidth              json.Number `json:"totalWidth"`
+	Extra                   Extra       `json:"-"`
 }
 
 type ExportFormat struct {
@@ -149,26 +207,33 @@ type ExportFormat struct {
 	NamingScheme     json.Number `json:"namingScheme"`
 	Scale            json.Number `json:"scale"`
 	VisibleScaleType json.Number `json:"visibleScaleType"`
+	Extra            Extra       `json:"-"`
 }
 
+// Color is an RGBA color. Archive holds the NSColor
+// of text attributes of documents before Sketch 48
 type Color struct {
-	Class string      `json:"_class"`
-	Alpha json.Number `json:"alpha"`
-	Blue  json.Number `json:"blue"`
-	Green json.Number `json:"green"`
-	Red   json.Number `json:"red"`
+	Class   string      `json:"_class"`
+	Alpha   json.Number `json:"alpha"`
+	Blue    json.Number `json:"blue"`
+	Green   json.Number `json:"green"`
+	Red     json.Number `json:"red"`
+	Archive *Archive    `json:"_archive,omitempty"`
+	Extra   Extra       `json:"-"`
 }
 
 type RulerData struct {
 	Class  string        `json:"_class"`
 	Base   json.Number   `json:"base"`
 	Guides []json.Number `json:"guides"`
+	Extra  Extra         `json:"-"`
 }
 
 type GraphicContextSettings struct {
 	Class     string      `json:"_class"`
 	BlendMode int64       `json:"blendMode"`
 	Opacity   json.Number `json:"opacity"`
+	Extra     Extra       `json:"-"`
 }
 
 type Shadow struct {
@@ -181,6 +246,7 @@ type Shadow struct {
 	OffsetX         json.Number             `json:"offsetX"`
 	OffsetY         json.Number             `json:"offsetY"`
 	Spread          json.Number             `json:"spread"`
+	Extra           Extra                   `json:"-"`
 }
 
 type InnerShadow struct {
@@ -194,6 +260,7 @@ type ColorControls struct {
 	Hue        json.Number `json:"hue"`
 	IsEnabled  bool        `json:"isEnabled"`
 	Saturation json.Number `json:"saturation"`
+	Extra      Extra       `json:"-"`
 }
 
 type Fill struct {
@@ -207,6 +274,7 @@ type Fill struct {
 	NoiseIntensity   json.Number `json:"noiseIntensity"`
 	PatternFillType  int64       `json:"patternFillType"`
 	PatternTileScale json.Number `json:"patternTileScale"`
+	Extra            Extra       `json:"-"`
 }
 
 type Border struct {
@@ -216,6 +284,7 @@ type Border struct {
 	IsEnabled bool        `json:"isEnabled"`
 	Position  int         `json:"position"`
 	Thickness json.Number `json:"thickness"`
+	Extra     Extra       `json:"-"`
 }
 
 type GradientStop struct {
@@ -223,6 +292,7 @@ type GradientStop struct {
 	DoObjectID string      `json:"do_objectID"`
 	Color      Color       `json:"color"`
 	Position   json.Number `json:"position"`
+	Extra      Extra       `json:"-"`
 }
 
 type Rect struct {
@@ -233,6 +303,7 @@ type Rect struct {
 	Width                json.Number `json:"width"`
 	X                    json.Number `json:"x"`
 	Y                    json.Number `json:"y"`
+	Extra                Extra       `json:"-"`
 }
 
 type CurvePoint struct {
@@ -244,6 +315,7 @@ type CurvePoint struct {
 	HasCurveFrom bool                 `json:"hasCurveFrom"`
 	HasCurveTo   bool                 `json:"hasCurveTo"`
 	Point        *PositionCoordinates `json:"point"`
+	Extra        Extra                `json:"-"`
 }
 
 type BorderOptions struct {
@@ -253,6 +325,7 @@ type BorderOptions struct {
 	IsEnabled     bool    `json:"isEnabled"`
 	LineCapStyle  int64   `json:"lineCapStyle"`
 	LineJoinStyle int64   `json:"lineJoinStyle"`
+	Extra         Extra   `json:"-"`
 }
 
 type Gradient struct {
@@ -264,12 +337,14 @@ type Gradient struct {
 	ShouldSmoothenOpacity bool                 `json:"shouldSmoothenOpacity"`
 	Stops                 []*GradientStop      `json:"stops"`
 	To                    *PositionCoordinates `json:"to"`
+	Extra                 Extra                `json:"-"`
 }
 
 type TextStyle struct {
 	Class             string             `json:"_class"`
 	EncodedAttributes *EncodedAttributes `json:"encodedAttributes"`
 	VerticalAlignment json.Number        `json:"verticalAlignment"`
+	Extra             Extra              `json:"-"`
 }
 
 type Style struct {
@@ -288,6 +363,7 @@ type Style struct {
 	Shadows             []*Shadow               `json:"shadows,omitempty"`
 	SharedObjectID      string                  `json:"sharedObjectID,omitempty"`
 	TextStyle           *TextStyle              `json:"textStyle,omitempty"`
+	Extra               Extra                   `json:"-"`
 }
 
 type SharedStyle struct {
@@ -295,21 +371,25 @@ type SharedStyle struct {
 	DoObjectID string `json:"do_objectID"`
 	Name       string `json:"name"`
 	Value      *Style `json:"value"`
+	Extra      Extra  `json:"-"`
 }
 
 type SharedStyleContainer struct {
 	Class   string         `json:"_class"`
-	Objects []*SharedStyle `json:"object"`
+	Objects []*SharedStyle `json:"objects"`
+	Extra   Extra          `json:"-"`
 }
 
 type SharedSymbolContainer struct {
-	Class   string        `json:"_class"`
-	Objects []interface{} `json:"object"`
+	Class   string `json:"_class"`
+	Objects Layers `json:"objects"`
+	Extra   Extra  `json:"-"`
 }
 
 type SharedTextStyleContainer struct {
 	Class   string         `json:"_class"`
 	Objects []*SharedStyle `json:"objects"`
+	Extra   Extra          `json:"-"`
 }
 
 type SharedAssetsCollection struct {
@@ -318,6 +398,7 @@ type SharedAssetsCollection struct {
 	Gradients       []*Gradient     `json:"gradients"`
 	ImageCollection ImageCollection `json:"imageCollection"`
 	Images          []Image         `json:"images"`
+	Extra           Extra           `json:"-"`
 }
 
 type Image interface{}
@@ -329,6 +410,7 @@ type Blur struct {
 	MotionAngle json.Number          `json:"motionAngle"`
 	Radius      json.Number          `json:"radius"`
 	Type        json.Number          `json:"type"`
+	Extra       Extra                `json:"-"`
 }
 
 type ExportOptions struct {
@@ -337,6 +419,7 @@ type ExportOptions struct {
 	IncludedLayerIds []string        `json:"includedLayerIds"`
 	LayerOptions     json.Number     `json:"layerOptions"`
 	ShouldTrim       bool            `json:"shouldTrim"`
+	Extra            Extra           `json:"-"`
 }
 
 type Path struct {
@@ -344,35 +427,105 @@ type Path struct {
 	PointRadiusBehaviour int64         `json:"pointRadiusBehaviour"`
 	IsClosed             bool          `json:"isClosed"`
 	Points               []*CurvePoint `json:"points"`
+	Extra                Extra         `json:"-"`
 }
 
+// EncodedAttributes are the attributes of a text style or a run of text.
+// Documents before Sketch 48 use the NS prefixed properties and archived
+// fonts, colors and paragraph styles, they are migrated to the plain
+// properties when parsed
 type EncodedAttributes struct {
-	NSKern                                   json.Number               `json:"NSKern,omitempty"`
-	NSStrokeWidth                            json.Number               `json:"NSStrokeWidth,omitempty"`
-	NSStrokeColor                            *ArchivedAttributedString `json:"NSStrokeColor,omitempty"`
-	NSStrikethrough                          json.Number               `json:"NSStrikethrough,omitempty"`
-	NSUnderline                              json.Number               `json:"NSUnderline,omitempty"`
-	MSAttributedStringFontAttribute          *ArchivedAttributedString `json:"MSAttributedStringFontAttribute,omitempty"`
-	MSAttributedStringTextTransformAttribute json.Number               `json:"MSAttributedStringTextTransformAttribute,omitempty"`
-	NSColor                                  *ArchivedAttributedString `json:"NSColor,omitempty"`
-	NSParagraphStyle                         *ArchivedAttributedString `json:"NSParagraphStyle,omitempty"`
+	Kerning                                  float64         `json:"kerning,omitempty"`
+	MSAttributedStringColorAttribute         *Color          `json:"MSAttributedStringColorAttribute,omitempty"`
+	ParagraphStyle                           *ParagraphStyle `json:"paragraphStyle,omitempty"`
+	StrikethroughStyle                       int64           `json:"strikethroughStyle,omitempty"`
+	TextStyleVerticalAlignmentKey            int64           `json:"textStyleVerticalAlignmentKey,omitempty"`
+	UnderlineStyle                           int64           `json:"underlineStyle,omitempty"`
+	NSKern                                   float64         `json:"NSKern,omitempty"`
+	NSStrokeWidth                            float64         `json:"NSStrokeWidth,omitempty"`
+	NSStrokeColor                            *Color          `json:"NSStrokeColor,omitempty"`
+	NSStrikethrough                          int64           `json:"NSStrikethrough,omitempty"`
+	NSUnderline                              int64           `json:"NSUnderline,omitempty"`
+	MSAttributedStringFontAttribute          *FontDescriptor `json:"MSAttributedStringFontAttribute,omitempty"`
+	MSAttributedStringTextTransformAttribute TextTransform   `json:"MSAttributedStringTextTransformAttribute,omitempty"`
+	NSColor                                  *Color          `json:"NSColor,omitempty"`
+	NSParagraphStyle                         *ParagraphStyle `json:"NSParagraphStyle,omitempty"`
+	Extra                                    Extra           `json:"-"`
 }
 
 type MSJSONFileReference struct {
-	Class    string     `json:"_class"`
-	Ref      string     `json:"_ref"`
-	RefClass string     `json:"_ref_class"`
-	Data     DataString `json:"data"`
-	Sha1     DataString `json:"sha1"`
+	Class    string      `json:"_class"`
+	Ref      string      `json:"_ref"`
+	RefClass string      `json:"_ref_class"`
+	Data     *DataString `json:"data,omitempty"`
+	Sha1     *DataString `json:"sha1,omitempty"`
+	Extra    Extra       `json:"-"`
 }
 
 type DataString struct {
-	Data string `json:"_data"`
+	Data  string `json:"_data"`
+	Extra Extra  `json:"-"`
 }
 
+// MSAttributedString is the content of a text layer. Documents before
+// Sketch 48 only hold the ArchivedAttributedString, String and Attributes
+// are migrated from it when parsed
 type MSAttributedString struct {
-	Class                    string                   `json:"_class"`
-	ArchivedAttributedString ArchivedAttributedString `json:"archivedAttributedString"`
+	Class                    string                    `json:"_class"`
+	String                   string                    `json:"string"`
+	Attributes               []*StringAttribute        `json:"attributes"`
+	ArchivedAttributedString *ArchivedAttributedString `json:"archivedAttributedString,omitempty"`
+	Extra                    Extra                     `json:"-"`
+}
+
+// StringAttribute styles Length characters of the string from Location,
+// counted in UTF-16 code units
+type StringAttribute struct {
+	Class      string             `json:"_class"`
+	Location   int64              `json:"location"`
+	Length     int64              `json:"length"`
+	Attributes *EncodedAttributes `json:"attributes"`
+	Extra      Extra              `json:"-"`
+}
+
+// ParagraphStyle is the paragraph style of a text style or a run of text.
+// Archive holds the NSParagraphStyle of documents before Sketch 48
+type ParagraphStyle struct {
+	Class             string      `json:"_class"`
+	Alignment         json.Number `json:"alignment,omitempty"`
+	MaximumLineHeight json.Number `json:"maximumLineHeight,omitempty"`
+	MinimumLineHeight json.Number `json:"minimumLineHeight,omitempty"`
+	ParagraphSpacing  json.Number `json:"paragraphSpacing,omitempty"`
+	Archive           *Archive    `json:"_archive,omitempty"`
+	Extra             Extra       `json:"-"`
+}
+
+// FontDescriptor is the font of a text style or a run of text.
+// Archive holds the NSFontDescriptor of documents before Sketch 48
+type FontDescriptor struct {
+	Class      string                    `json:"_class"`
+	Attributes *FontDescriptorAttributes `json:"attributes"`
+	Archive    *Archive                  `json:"_archive,omitempty"`
+	Extra      Extra                     `json:"-"`
+}
+
+type FontDescriptorAttributes struct {
+	// Name is the PostScript name of the font
+	Name  string      `json:"name"`
+	Size  json.Number `json:"size"`
+	Extra Extra       `json:"-"`
+}
+
+// ForeignSymbol is a symbol master imported from a library
+type ForeignSymbol struct {
+	Class             string        `json:"_class"`
+	DoObjectID        string        `json:"do_objectID"`
+	LibraryID         string        `json:"libraryID"`
+	SourceLibraryName string        `json:"sourceLibraryName"`
+	SymbolPrivate     bool          `json:"symbolPrivate"`
+	OriginalMaster    *SymbolMaster `json:"originalMaster"`
+	SymbolMaster      *SymbolMaster `json:"symbolMaster"`
+	Extra             Extra         `json:"-"`
 }
 
 type AssetsCollection struct {
@@ -381,45 +534,52 @@ type AssetsCollection struct {
 	Gradients       []*Gradient            `json:"gradients"`
 	ImageCollection *ImageCollection       `json:"imageCollection"`
 	Images          []*MSJSONFileReference `json:"images"`
+	Extra           Extra                  `json:"-"`
 }
 
 type ImageCollection struct {
 	Class  string               `json:"_class"`
 	Images *MSJSONFileReference `json:"images"`
+	Extra  Extra                `json:"-"`
 }
 
 type Overrides map[string]interface{}
 
 // Example `{0.5, 0.67135115527602085}`
 type PositionCoordinates struct {
-	X    json.Number
-	Y    json.Number
-	data []byte
+	X json.Number
+	Y json.Number
 }
 
-func (p *PositionCoordinates) MarshalJSON() ([]byte, error) {
-	// TODO: fix marshal function to properly encode
-	out := fmt.Sprintf("{%v, %v}", p.X, p.Y)
-	out = strconv.Quote(out)
-	return []byte(out), nil
+func (p PositionCoordinates) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
 }
 
-func (p *PositionCoordinates) UnmarshalJSON(b []byte) error {
-	p.data = b
-	b = bytes.Replace(b, []byte("{"), []byte("["), -1)
-	b = bytes.Replace(b, []byte("}"), []byte("]"), -1)
+// String formats the point the way Sketch stores it, `{x, y}`
+func (p PositionCoordinates) String() string {
+	return fmt.Sprintf("{%v, %v}", numberOrZero(p.X), numberOrZero(p.Y))
+}
 
-	s, err := strconv.Unquote(string(b))
-	b = []byte(s)
-	if err != nil {
-		return err
+func numberOrZero(n json.Number) json.Number {
+	if n == "" {
+		return "0"
+	}
+	return n
+}
+
+func (p *PositionCoordinates) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
 	}
 
 	data := []json.Number{}
-
-	if err := json.Unmarshal(b, &data); err != nil {
+	s, err := decodeBraces(b, &data)
+	if err != nil {
 		return err
 	}
+	if len(data) != 2 {
+		return errors.Errorf("point %q: want 2 coordinates, got %d", s, len(data))
+	}
 
 	p.X = data[0]
 	p.Y = data[1]
@@ -427,41 +587,54 @@ func (p *PositionCoordinates) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// decodeBraces decodes a JSON string holding Sketch's `{a, b}` notation
+// into dst as if the braces were JSON array brackets. It returns the
+// string for use in error messages
+func decodeBraces(b []byte, dst interface{}) (string, error) {
+	s := ""
+	if err := json.Unmarshal(b, &s); err != nil {
+		return "", errors.Wrap(err, "point")
+	}
+
+	r := strings.NewReplacer("{", "[", "}", "]")
+	if err := json.Unmarshal([]byte(r.Replace(s)), dst); err != nil {
+		return s, errors.Wrapf(err, "point %q", s)
+	}
+	return s, nil
+}
+
 // Example `{{0.5, 0.67135115527602085}, {0.5, 0.67135115527602085}}`
 type NestedPositionCoordinates struct {
 	Data []*PositionCoordinates
 }
 
-func (n *NestedPositionCoordinates) MarshalJSON() ([]byte, error) {
-	// TODO: fix marshal function to properly encode
-	out := ""
-	for i, p := range n.Data {
-		out += fmt.Sprintf("{%v, %v}", p.X, p.Y)
-		if i != (len(n.Data) - 1) {
-			out += ","
+func (n NestedPositionCoordinates) MarshalJSON() ([]byte, error) {
+	points := make([]string, 0, len(n.Data))
+	for _, p := range n.Data {
+		if p == nil {
+			p = &PositionCoordinates{}
 		}
+		points = append(points, p.String())
 	}
-	out = "\"{" + out + "}\""
-	return []byte(out), nil
+	return json.Marshal("{" + strings.Join(points, ", ") + "}")
 }
 
 func (n *NestedPositionCoordinates) UnmarshalJSON(b []byte) error {
-	b = bytes.Replace(b, []byte("{"), []byte("["), -1)
-	b = bytes.Replace(b, []byte("}"), []byte("]"), -1)
-
-	s, err := strconv.Unquote(string(b))
-	b = []byte(s)
-	if err != nil {
-		return err
+	if string(b) == "null" {
+		return nil
 	}
 
 	data := [][]json.Number{}
-
-	if err := json.Unmarshal(b, &data); err != nil {
+	s, err := decodeBraces(b, &data)
+	if err != nil {
 		return err
 	}
 
-	for _, posAr := range data {
+	n.Data = make([]*PositionCoordinates, 0, len(data))
+	for i, posAr := range data {
+		if len(posAr) != 2 {
+			return errors.Errorf("point %d of %q: want 2 coordinates, got %d", i, s, len(posAr))
+		}
 		n.Data = append(n.Data, &PositionCoordinates{X: posAr[0], Y: posAr[1]})
 	}
 	return nil
@@ -469,42 +642,106 @@ func (n *NestedPositionCoordinates) UnmarshalJSON(b []byte) error {
 
 type ArchivedAttributedString struct {
 	Archive Archive `json:"_archive"`
+	Extra   Extra   `json:"-"`
 }
 
+// Archive is a base64 encoded NSKeyedArchiver binary plist.
+// The plist is only decoded when Data is first called
 type Archive struct {
-	Data map[string]interface{}
-	data []byte
+	// raw is the plist as read, written back unchanged while the
+	// decoded data still matches it so archives stay byte for byte identical
+	raw []byte
+
+	// plist is shared by copies of the archive so it is decoded once
+	plist *archivePlist
 }
 
-func (n Archive) MarshalJSON() ([]byte, error) {
-	// TODO: fix marshal function to properly encode
-	out, err := plist.Marshal(n, plist.BinaryFormat)
-	if err != nil {
-		return out, errors.Wrap(err, "Archive.Marshal.JSON")
-	}
+type archivePlist struct {
+	once    sync.Once
+	decoded atomic.Bool
+	data    map[string]interface{}
+	err     error
+}
 
-	encOut := base64.StdEncoding.EncodeToString([]byte(out))
+// Data returns the decoded plist of the archive
+func (a Archive) Data() (map[string]interface{}, error) {
+	if a.plist == nil {
+		return nil, nil
+	}
 
-	return []byte(encOut), nil
+	a.plist.once.Do(func() {
+		data := map[string]interface{}{}
+		if err := unmarshalPlist(a.raw, &data); err != nil {
+			a.plist.err = errors.Wrap(err, "archive")
+		} else {
+			a.plist.data = data
+		}
+		a.plist.decoded.Store(true)
+	})
+	return a.plist.data, a.plist.err
 }
 
-func (a *Archive) UnmarshalJSON(b []byte) error {
-	a.data = b
-	ad := map[string]interface{}{}
-	a.data = b
-	d := []byte{}
+// SetData replaces the content of the archive
+func (a *Archive) SetData(data map[string]interface{}) {
+	a.raw = nil
+	a.plist = &archivePlist{data: data}
+	a.plist.once.Do(func() {})
+	a.plist.decoded.Store(true)
+}
 
-	err := json.Unmarshal(b, &d)
+func (a Archive) MarshalJSON() ([]byte, error) {
+	out, err := a.encode()
 	if err != nil {
-		return err
+		return nil, errors.Wrap(err, "Archive.Marshal.JSON")
+	}
+
+	return json.Marshal(out)
+}
+
+// encode returns the binary plist of the archive, reusing the read
+// bytes unless the decoded data was changed since
+func (a Archive) encode() ([]byte, error) {
+	if a.plist == nil {
+		return []byte{}, nil
+	}
+	if a.raw != nil && !a.plist.decoded.Load() {
+		return a.raw, nil
 	}
 
-	_, err = plist.Unmarshal(d, &ad)
+	data, err := a.Data()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if a.raw != nil {
+		orig := map[string]interface{}{}
+		if err := unmarshalPlist(a.raw, &orig); err == nil && reflect.DeepEqual(orig, data) {
+			return a.raw, nil
+		}
 	}
 
-	a.Data = ad
+	return plist.Marshal(data, plist.BinaryFormat)
+}
+
+func (a *Archive) UnmarshalJSON(b []byte) error {
+	d := []byte{}
+	if err := json.Unmarshal(b, &d); err != nil {
+		return errors.Wrap(err, "archive")
+	}
 
+	a.raw = d
+	a.plist = &archivePlist{}
 	return nil
 }
+
+// unmarshalPlist decodes a plist, turning panics of the plist
+// package on malformed input into errors
+func unmarshalPlist(b []byte, dst interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("malformed plist: %v", r)
+		}
+	}()
+
+	_, err = plist.Unmarshal(b, dst)
+	return err
+}
@@ -0,0 +1,220 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// coordinates is testdata/coordinates.json, holding the values Sketch
+// writes in its own notations, each to be encoded back unchanged. The
+// archives use wider object references than howett.net/plist writes,
+// so only reusing the bytes as read encodes them back identically
+type coordinates struct {
+	Points       []json.RawMessage `json:"points"`
+	NestedPoints []json.RawMessage `json:"nestedPoints"`
+	Archives     []json.RawMessage `json:"archives"`
+}
+
+func readCoordinates(t testing.TB) *coordinates {
+	t.Helper()
+	b, err := os.ReadFile("testdata/coordinates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &coordinates{}
+	if err := json.Unmarshal(b, c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// assertReencodes decodes in into v and fails unless encoding v gives in back
+func assertReencodes(t *testing.T, in []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(in, v); err != nil {
+		t.Fatalf("%s: %v", in, err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("%s: %v", in, err)
+	}
+	if string(out) != string(in) {
+		t.Errorf("got %s, want %s", out, in)
+	}
+}
+
+func TestPositionCoordinatesReencode(t *testing.T) {
+	c := readCoordinates(t)
+	for _, raw := range c.Points {
+		assertReencodes(t, raw, &PositionCoordinates{})
+	}
+	for _, raw := range c.NestedPoints {
+		assertReencodes(t, raw, &NestedPositionCoordinates{})
+	}
+}
+
+func TestArchiveReencode(t *testing.T) {
+	c := readCoordinates(t)
+	for _, raw := range c.Archives {
+		a := &Archive{}
+		assertReencodes(t, raw, a)
+
+		// decoding the plist keeps the bytes as read while the data is unchanged
+		if _, err := a.Data(); err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != string(raw) {
+			t.Errorf("after Data got %s, want %s", out, raw)
+		}
+
+		// and so does a copy sharing the decoded plist
+		out, err = json.Marshal(*a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != string(raw) {
+			t.Errorf("copy after Data got %s, want %s", out, raw)
+		}
+	}
+
+	wrapped := []byte(`{"_archive":` + string(c.Archives[0]) + `}`)
+	assertReencodes(t, wrapped, &ArchivedAttributedString{})
+}
+
+func TestArchiveReencodeChanged(t *testing.T) {
+	c := readCoordinates(t)
+	a := &Archive{}
+	if err := json.Unmarshal(c.Archives[1], a); err != nil {
+		t.Fatal(err)
+	}
+	data, err := a.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data["$version"] = uint64(200000)
+	a.SetData(data)
+
+	out, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) == string(c.Archives[1]) {
+		t.Fatal("changed archive encoded as read")
+	}
+
+	got := &Archive{}
+	if err := json.Unmarshal(out, got); err != nil {
+		t.Fatal(err)
+	}
+	gotData, err := got.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotData["$version"] != uint64(200000) {
+		t.Fatalf("got $version %v, want 200000", gotData["$version"])
+	}
+}
+
+func TestMeta(t *testing.T) {
+	fsys := minimalFS()
+	fsys["meta.json"].Data = []byte(`{"commit":"c0ffee","appVersion":"70.3","build":109185,` +
+		`"app":"com.bohemiancoding.sketch3","compatibilityVersion":99,"version":146,` +
+		`"pagesAndArtboards":{"P1":{"name":"Page 1","artboards":{"A1":{"name":"Login"},"A2":{"name":"Signup"}}},"P2":{"name":"Empty","artboards":{}}},` +
+		`"fonts":["Inter-Bold"],"saveHistory":["NONAPPSTORE.38999","BETA.109185","41000"],"autosaved":0,"variant":"NONAPPSTORE",` +
+		`"created":{"commit":"abc","appVersion":"49.1","build":51167,"app":"com.bohemiancoding.sketch3","compatibilityVersion":99,"version":105,"variant":"NONAPPSTORE"}}`)
+
+	f, err := ParseFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := f.Meta
+	if m.AppVersion != "70.3" || m.Build != "109185" || m.Version != "146" || m.Variant != "NONAPPSTORE" || !reflect.DeepEqual(m.Fonts, []string{"Inter-Bold"}) {
+		t.Fatalf("got meta %+v", m)
+	}
+	if m.Created == nil || m.Created.AppVersion != "49.1" || m.Created.Build != "51167" || m.Created.Version != "105" {
+		t.Fatalf("got created %+v", m.Created)
+	}
+
+	pages := map[string]map[string]string{}
+	for id, p := range m.PagesAndArtboards {
+		artboards := map[string]string{}
+		for aid, a := range p.Artboards {
+			artboards[aid] = a.Name
+		}
+		pages[id+" "+p.Name] = artboards
+	}
+	wantPages := map[string]map[string]string{
+		"P1 Page 1": {"A1": "Login", "A2": "Signup"},
+		"P2 Empty":  {},
+	}
+	if !reflect.DeepEqual(pages, wantPages) {
+		t.Fatalf("got pagesAndArtboards %v, want %v", pages, wantPages)
+	}
+
+	wantHistory := []*SaveHistoryEntry{
+		{Variant: "NONAPPSTORE", Build: "38999"},
+		{Variant: "BETA", Build: "109185"},
+		{Build: "41000"},
+	}
+	if !reflect.DeepEqual(m.SaveHistory, wantHistory) {
+		t.Fatalf("got save history %+v", m.SaveHistory)
+	}
+	for i, want := range []string{`"NONAPPSTORE.38999"`, `"BETA.109185"`, `"41000"`} {
+		assertReencodes(t, []byte(want), &SaveHistoryEntry{})
+		if b, err := json.Marshal(m.SaveHistory[i]); err != nil || string(b) != want {
+			t.Errorf("entry %d encodes to %s, %v, want %s", i, b, err, want)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`38999`), &SaveHistoryEntry{}); err == nil {
+		t.Error("decoded a save history entry that is not a string")
+	}
+}
+
+func TestUserState(t *testing.T) {
+	user := `{"document":{"pageListHeight":110,"pageListCollapsed":0,"expandedSymbolPathsInSidebar":["Buttons"]},` +
+		`"P1":{"scrollOrigin":"{100, -200.5}","zoomValue":0.5,"lastUsedTool":3},` +
+		`"P2":{"scrollOrigin":"{0, 0}","zoomValue":1}}`
+	fsys := minimalFS()
+	fsys["user.json"] = &fstest.MapFile{Data: []byte(user)}
+
+	f, err := ParseFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := f.User.Document
+	if d == nil || d.PageListHeight != "110" || !reflect.DeepEqual(d.ExpandedSymbolPathsInSidebar, []string{"Buttons"}) {
+		t.Fatalf("got document state %+v", d)
+	}
+	p1 := f.User.Pages["P1"]
+	if len(f.User.Pages) != 2 || p1 == nil || p1.ZoomValue != "0.5" || p1.ScrollOrigin == nil || p1.ScrollOrigin.X != "100" || p1.ScrollOrigin.Y != "-200.5" {
+		t.Fatalf("got page states %+v", f.User.Pages)
+	}
+	if _, ok := f.User.Pages["document"]; ok {
+		t.Fatal("the document state is listed as a page")
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := f.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(user), &want); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEntry(t, buf.Bytes(), "user.json"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got user.json %v, want %v", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"P1":{"scrollOrigin":"{1}"}}`), &UserState{}); err == nil {
+		t.Error("decoded a scroll origin of one coordinate")
+	}
+}
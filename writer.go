@@ -0,0 +1,238 @@
+package sketch
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Save writes the file to dst as a sketch archive
+func (f *File) Save(dst string) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteTo(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// WriteTo writes the file to w as a sketch archive. The page references
+// of the document and the page index of meta.json are rebuilt from Pages
+// and nil pages are skipped. Properties Sketch needs to open the file are
+// filled in when unset, on copies so f is not modified
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	zw := zip.NewWriter(cw)
+
+	doc, meta, pages, err := f.syncPages()
+	if err != nil {
+		return cw.n, err
+	}
+
+	if err := writeObj(zw, "document.json", doc); err != nil {
+		return cw.n, err
+	}
+	if err := writeObj(zw, "meta.json", meta); err != nil {
+		return cw.n, err
+	}
+	if err := writeObj(zw, "user.json", &f.User); err != nil {
+		return cw.n, err
+	}
+
+	for _, page := range pages {
+		if err := writeObj(zw, pageRef(page.DoObjectID)+".json", page); err != nil {
+			return cw.n, err
+		}
+	}
+
+	for _, files := range []map[string][]byte{f.Images, f.Previews} {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fw, err := zw.Create(name)
+			if err != nil {
+				return cw.n, err
+			}
+			if _, err := fw.Write(files[name]); err != nil {
+				return cw.n, err
+			}
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// Defaults written for a File built in code
+const (
+	// sketchApp is the bundle identifier of Sketch
+	sketchApp = "com.bohemiancoding.sketch3"
+	// compatibilityVersion is the oldest format version
+	// able to open documents of MaxVersion
+	compatibilityVersion = 99
+)
+
+// syncPages returns copies of the document, meta and the non nil pages
+// with the page references and the page and artboard index matching
+// Pages, and the properties Sketch needs filled in
+func (f *File) syncPages() (*Document, *Meta, []*Page, error) {
+	doc := f.Document
+	meta := f.Meta
+
+	if doc.Class == "" {
+		doc.Class = "document"
+	}
+	if doc.DoObjectID == "" {
+		id, err := newObjectID()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		doc.DoObjectID = id
+	}
+	if meta.Version == "" {
+		meta.Version = json.Number(strconv.Itoa(MaxVersion))
+	}
+	if meta.CompatibilityVersion == "" {
+		meta.CompatibilityVersion = json.Number(strconv.Itoa(compatibilityVersion))
+	}
+	if meta.App == "" {
+		meta.App = sketchApp
+	}
+
+	refs := map[string]*MSJSONFileReference{}
+	for _, ref := range f.Document.Pages {
+		if ref != nil {
+			refs[ref.Ref] = ref
+		}
+	}
+
+	doc.Pages = make([]*MSJSONFileReference, 0, len(f.Pages))
+	meta.PagesAndArtboards = map[string]*PageMeta{}
+	pages := make([]*Page, 0, len(f.Pages))
+	for _, page := range f.Pages {
+		if page == nil {
+			continue
+		}
+		if page.DoObjectID == "" {
+			return nil, nil, nil, errors.Errorf("page %q has no do_objectID", page.Name)
+		}
+		if _, ok := meta.PagesAndArtboards[page.DoObjectID]; ok {
+			return nil, nil, nil, errors.Errorf("duplicate page do_objectID %q", page.DoObjectID)
+		}
+
+		ref, ok := refs[pageRef(page.DoObjectID)]
+		if !ok {
+			ref = &MSJSONFileReference{
+				Class:    "MSJSONFileReference",
+				Ref:      pageRef(page.DoObjectID),
+				RefClass: "MSImmutablePage",
+			}
+		}
+		doc.Pages = append(doc.Pages, ref)
+
+		meta.PagesAndArtboards[page.DoObjectID] = pageMeta(f.Meta.PagesAndArtboards[page.DoObjectID], page)
+		pages = append(pages, pageDefaults(page))
+	}
+
+	return &doc, &meta, pages, nil
+}
+
+// pageMeta returns a copy of the meta.json index entry old of page,
+// with the names and artboards of page. Entries of old for artboards
+// still on the page keep their undeclared properties
+func pageMeta(old *PageMeta, page *Page) *PageMeta {
+	pm := &PageMeta{}
+	if old != nil {
+		*pm = *old
+	}
+	pm.Name = page.Name
+	pm.Artboards = map[string]*ArtboardMeta{}
+
+	for _, layer := range page.Layers {
+		switch layer.(type) {
+		case *Artboard, *SymbolMaster:
+			base := layer.Base()
+			am := &ArtboardMeta{}
+			if old != nil && old.Artboards[base.DoObjectID] != nil {
+				*am = *old.Artboards[base.DoObjectID]
+			}
+			am.Name = base.Name
+			pm.Artboards[base.DoObjectID] = am
+		}
+	}
+	return pm
+}
+
+// pageDefaults returns a copy of page with the `_class`, frame and
+// layers Sketch expects of every page set
+func pageDefaults(page *Page) *Page {
+	p := *page
+	if p.Class == "" {
+		p.Class = "page"
+	}
+	if p.Frame == nil {
+		p.Frame = &Rect{Class: "rect", Height: "0", Width: "0", X: "0", Y: "0"}
+	}
+	if p.Layers == nil {
+		p.Layers = Layers{}
+	}
+	return &p
+}
+
+// newObjectID returns a random do_objectID, an upper case UUID
+// like the ones Sketch generates
+func newObjectID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return strings.ToUpper(fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:])), nil
+}
+
+// pageRef is the reference to a page from the document,
+// the page itself is stored in the archive at pageRef(id) + ".json"
+func pageRef(id string) string {
+	return "pages/" + id
+}
+
+func writeObj(zw *zip.Writer, name string, src interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(src)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
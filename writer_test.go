@@ -0,0 +1,138 @@
+package sketch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestWriteToSkipsNilPagesAndSetsClasses(t *testing.T) {
+	f := &File{
+		Pages: []*Page{
+			nil,
+			{
+				DoObjectID: "P1",
+				Name:       "Page 1",
+				Layers: Layers{
+					&Artboard{
+						GroupBase: GroupBase{
+							LayerBase: LayerBase{DoObjectID: "A1", Name: "Home"},
+							Layers:    Layers{&Text{LayerBase: LayerBase{DoObjectID: "T1"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := f.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Pages) != 1 || len(got.Document.Pages) != 1 {
+		t.Fatalf("got %d pages and %d page references, want 1", len(got.Pages), len(got.Document.Pages))
+	}
+	artboard, ok := got.Pages[0].Layers[0].(*Artboard)
+	if !ok {
+		t.Fatalf("layer decoded as %T, want *Artboard", got.Pages[0].Layers[0])
+	}
+	if _, ok := artboard.Layers[0].(*Text); !ok {
+		t.Fatalf("layer decoded as %T, want *Text", artboard.Layers[0])
+	}
+	if got.Meta.PagesAndArtboards["P1"].Artboards["A1"].Name != "Home" {
+		t.Fatalf("artboard missing from meta: %+v", got.Meta.PagesAndArtboards["P1"])
+	}
+}
+
+// readEntry returns the JSON entry name of the sketch archive b
+func readEntry(t *testing.T, b []byte, name string) map[string]interface{} {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	v := map[string]interface{}{}
+	if err := json.NewDecoder(f).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestWriteToDefaults(t *testing.T) {
+	text := &Text{LayerBase: LayerBase{DoObjectID: "T1"}}
+	f := &File{
+		Meta: Meta{
+			PagesAndArtboards: map[string]*PageMeta{
+				"P1": {
+					Name:  "Old name",
+					Extra: Extra{Fields: map[string]json.RawMessage{"x": json.RawMessage(`1`)}},
+					Artboards: map[string]*ArtboardMeta{
+						"A1":   {Name: "Old", Extra: Extra{Fields: map[string]json.RawMessage{"y": json.RawMessage(`2`)}}},
+						"gone": {Name: "Deleted"},
+					},
+				},
+			},
+		},
+		Pages: []*Page{
+			{DoObjectID: "P1", Name: "Page 1", Layers: Layers{
+				&Artboard{GroupBase: GroupBase{LayerBase: LayerBase{DoObjectID: "A1", Name: "Home"}}},
+				text,
+			}},
+			{DoObjectID: "P2", Name: "Page 2"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := f.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	meta := readEntry(t, b, "meta.json")
+	if meta["version"] != float64(MaxVersion) || meta["compatibilityVersion"] != float64(99) || meta["app"] != "com.bohemiancoding.sketch3" {
+		t.Errorf("got meta %v", meta)
+	}
+	doc := readEntry(t, b, "document.json")
+	if id, _ := doc["do_objectID"].(string); doc["_class"] != "document" || !regexp.MustCompile(`^[0-9A-F]{8}(-[0-9A-F]{4}){3}-[0-9A-F]{12}$`).MatchString(id) {
+		t.Errorf("got document %v", doc)
+	}
+	page := readEntry(t, b, "pages/P2.json")
+	if layers, ok := page["layers"].([]interface{}); page["_class"] != "page" || page["frame"] == nil || !ok || len(layers) != 0 {
+		t.Errorf("got page %v", page)
+	}
+
+	got, err := ParseBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm := got.Meta.PagesAndArtboards["P1"]
+	if pm.Name != "Page 1" || string(pm.Extra.Fields["x"]) != "1" || len(pm.Artboards) != 1 {
+		t.Fatalf("got page index %+v", pm)
+	}
+	if am := pm.Artboards["A1"]; am.Name != "Home" || string(am.Extra.Fields["y"]) != "2" {
+		t.Fatalf("got artboard index %+v", am)
+	}
+	if _, ok := got.Pages[0].Layers[1].(*Text); !ok {
+		t.Fatalf("layer decoded as %T, want *Text", got.Pages[0].Layers[1])
+	}
+
+	// the file written is left as it was
+	old := f.Meta.PagesAndArtboards["P1"]
+	if f.Document.DoObjectID != "" || f.Meta.Version != "" || f.Pages[1].Class != "" || f.Pages[1].Frame != nil ||
+		f.Pages[1].Layers != nil || text.Class != "" || old.Name != "Old name" || old.Artboards["A1"].Name != "Old" || len(old.Artboards) != 2 {
+		t.Fatalf("WriteTo modified the file: %+v", f)
+	}
+}
@@ -0,0 +1,139 @@
+package sketch
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+type xliff12 struct {
+	XMLName xml.Name      `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string        `xml:"version,attr"`
+	Files   []xliff12File `xml:"file"`
+}
+
+type xliff12File struct {
+	Original       string        `xml:"original,attr"`
+	SourceLanguage string        `xml:"source-language,attr"`
+	TargetLanguage string        `xml:"target-language,attr,omitempty"`
+	Datatype       string        `xml:"datatype,attr"`
+	Units          []xliff12Unit `xml:"body>trans-unit"`
+}
+
+type xliff12Unit struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source"`
+	Target string `xml:"target,omitempty"`
+	Note   string `xml:"note,omitempty"`
+}
+
+type xliff20 struct {
+	XMLName xml.Name      `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string        `xml:"version,attr"`
+	SrcLang string        `xml:"srcLang,attr"`
+	TrgLang string        `xml:"trgLang,attr,omitempty"`
+	Files   []xliff20File `xml:"file"`
+}
+
+type xliff20File struct {
+	ID    string        `xml:"id,attr"`
+	Units []xliff20Unit `xml:"unit"`
+}
+
+type xliff20Unit struct {
+	ID     string   `xml:"id,attr"`
+	Notes  []string `xml:"notes>note,omitempty"`
+	Source string   `xml:"segment>source"`
+	Target string   `xml:"segment>target,omitempty"`
+}
+
+// WriteXLIFF12 writes the catalog as an XLIFF 1.2 document
+func WriteXLIFF12(w io.Writer, c *Catalog) error {
+	file := xliff12File{
+		Original:       "document.json",
+		SourceLanguage: c.SourceLocale,
+		TargetLanguage: c.Locale,
+		Datatype:       "plaintext",
+	}
+	for _, t := range c.Translations {
+		file.Units = append(file.Units, xliff12Unit{ID: t.Key, Source: t.Source, Target: t.Target, Note: t.Note})
+	}
+	return writeXML(w, xliff12{Version: "1.2", Files: []xliff12File{file}})
+}
+
+// WriteXLIFF20 writes the catalog as an XLIFF 2.0 document
+func WriteXLIFF20(w io.Writer, c *Catalog) error {
+	file := xliff20File{ID: "document"}
+	for _, t := range c.Translations {
+		unit := xliff20Unit{ID: t.Key, Source: t.Source, Target: t.Target}
+		if t.Note != "" {
+			unit.Notes = []string{t.Note}
+		}
+		file.Units = append(file.Units, unit)
+	}
+	return writeXML(w, xliff20{Version: "2.0", SrcLang: c.SourceLocale, TrgLang: c.Locale, Files: []xliff20File{file}})
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadXLIFF reads a catalog from an XLIFF 1.2 or 2.0 document
+func ReadXLIFF(r io.Reader) (*Catalog, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	head := struct {
+		Version string `xml:"version,attr"`
+	}{}
+	if err := xml.Unmarshal(b, &head); err != nil {
+		return nil, errors.Wrap(err, "xliff")
+	}
+
+	c := &Catalog{}
+	switch head.Version {
+	case "1.2":
+		doc := xliff12{}
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, errors.Wrap(err, "xliff")
+		}
+		for _, file := range doc.Files {
+			c.SourceLocale = file.SourceLanguage
+			c.Locale = file.TargetLanguage
+			for _, u := range file.Units {
+				c.Translations = append(c.Translations, &Translation{Key: u.ID, Source: u.Source, Target: u.Target, Note: u.Note})
+			}
+		}
+	case "2.0":
+		doc := xliff20{}
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, errors.Wrap(err, "xliff")
+		}
+		c.SourceLocale = doc.SrcLang
+		c.Locale = doc.TrgLang
+		for _, file := range doc.Files {
+			for _, u := range file.Units {
+				t := &Translation{Key: u.ID, Source: u.Source, Target: u.Target}
+				if len(u.Notes) > 0 {
+					t.Note = u.Notes[0]
+				}
+				c.Translations = append(c.Translations, t)
+			}
+		}
+	default:
+		return nil, errors.Errorf("unsupported XLIFF version %q", head.Version)
+	}
+	return c, nil
+}